@@ -6,48 +6,171 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"pg-sec-lab/internal/configcheck"
+	"pg-sec-lab/internal/policy"
+	"pg-sec-lab/internal/sarif"
+	"pg-sec-lab/pkg/checker"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/cobra"
 )
 
 var (
-	analyzeDsn     string
-	analyzeOutFile string
+	analyzeDsn      string
+	analyzeOutFile  string
+	analyzeFormat   string
+	analyzePolicy   string
+	analyzeSettings string
+	analyzeProfile  string
+	analyzeReleases string
+	analyzeConnLogs []string
+	analyzeHistory  []string
+	analyzeClients  []string
+	analyzeAllDBs   bool
+	analyzeInclude  []string
+	analyzeExclude  []string
+	analyzeOpts     configcheck.Options
 )
 
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze",
 	Short: "Analyze PostgreSQL configuration",
-	Long:  `Analyze PostgreSQL instance for security configuration and generate JSON report`,
+	Long:  `Analyze PostgreSQL instance for security configuration and generate JSON or SARIF report`,
 	RunE:  runAnalyze,
 }
 
 func init() {
 	rootCmd.AddCommand(analyzeCmd)
 	analyzeCmd.Flags().StringVar(&analyzeDsn, "dsn", "", "database connection string (required)")
-	analyzeCmd.Flags().StringVar(&analyzeOutFile, "out", "report.json", "output JSON file")
+	analyzeCmd.Flags().StringVar(&analyzeOutFile, "out", "report.json", "output file")
+	analyzeCmd.Flags().StringVar(&analyzeFormat, "format", "json", "output format: json or sarif")
+	analyzeCmd.Flags().StringSliceVar(&analyzeOpts.SensitiveDatabases, "sensitive-db", nil, "databases where CONNECT for PUBLIC is reported (repeatable)")
+	analyzeCmd.Flags().StringVar(&analyzePolicy, "policy", "", "policy file used to check masked columns (optional)")
+	analyzeCmd.Flags().StringVar(&analyzeSettings, "settings", "", "settings catalog overrides for this environment (optional)")
+	analyzeCmd.Flags().StringVar(&analyzeProfile, "profile", "", "benchmark profile to evaluate, e.g. cis-16 (optional)")
+	analyzeCmd.Flags().StringVar(&analyzeReleases, "releases", "", "release and CVE dataset replacing the bundled one (optional)")
+	analyzeCmd.Flags().IntVar(&analyzeOpts.PIISampleRows, "pii-sample", 0, "rows per table to sample when classifying PII columns, at most 1000 (0 disables sampling)")
+	analyzeCmd.Flags().StringSliceVar(&analyzeConnLogs, "connection-log", nil, "PostgreSQL log with log_connections lines used to find dormant roles (repeatable)")
+	analyzeCmd.Flags().StringSliceVar(&analyzeHistory, "activity-report", nil, "earlier analyze report whose role activity is carried over (repeatable)")
+	analyzeCmd.Flags().StringSliceVar(&analyzeClients, "client-allowlist", nil, "addresses or CIDR ranges clients may connect from (repeatable)")
+	analyzeCmd.Flags().DurationVar(&analyzeOpts.IdleInTransaction, "idle-in-transaction", 5*time.Minute, "report sessions idle in a transaction with locks for longer than this")
+	analyzeCmd.Flags().BoolVar(&analyzeAllDBs, "all-databases", false, "analyze every database of the cluster, not only the one in the DSN")
+	analyzeCmd.Flags().StringSliceVar(&analyzeInclude, "include-db", nil, "with --all-databases, only databases matching these patterns (repeatable)")
+	analyzeCmd.Flags().StringSliceVar(&analyzeExclude, "exclude-db", nil, "with --all-databases, skip databases matching these patterns (repeatable)")
+	analyzeCmd.Flags().IntVar(&analyzeOpts.Concurrency, "concurrency", 4, "catalog queries run at once, also the connection limit per database")
 	analyzeCmd.MarkFlagRequired("dsn")
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
-	conn, err := pgx.Connect(ctx, analyzeDsn)
+	if analyzeFormat != "json" && analyzeFormat != "sarif" {
+		return fmt.Errorf("unknown format %q (expected json or sarif)", analyzeFormat)
+	}
+
+	if analyzePolicy != "" {
+		p, err := policy.Load(analyzePolicy)
+		if err != nil {
+			return fmt.Errorf("failed to load policy: %w", err)
+		}
+		for tableName, tp := range p.Tables {
+			for _, mask := range tp.Masks {
+				analyzeOpts.MaskedColumns = append(analyzeOpts.MaskedColumns, configcheck.MaskedColumn{
+					Table:  tableName,
+					Column: mask.Column,
+				})
+			}
+		}
+	}
+
+	if analyzeSettings != "" {
+		checks, err := checker.LoadSettingChecks(analyzeSettings)
+		if err != nil {
+			return err
+		}
+		analyzeOpts.SettingChecks = checks
+	}
+
+	if analyzeProfile != "" {
+		p, err := checker.LoadProfile(analyzeProfile)
+		if err != nil {
+			return err
+		}
+		analyzeOpts.Profile = p
+	}
+
+	if analyzeReleases != "" {
+		data, err := checker.LoadReleaseData(analyzeReleases)
+		if err != nil {
+			return err
+		}
+		analyzeOpts.Releases = data
+	}
+
+	if len(analyzeConnLogs) > 0 || len(analyzeHistory) > 0 {
+		analyzeOpts.RoleHistory = make(map[string]time.Time)
+		for _, path := range analyzeConnLogs {
+			if err := checker.ParseConnectionLog(path, analyzeOpts.RoleHistory); err != nil {
+				return err
+			}
+		}
+		for _, path := range analyzeHistory {
+			previous, err := checker.LoadReport(path)
+			if err != nil {
+				return err
+			}
+			checker.ActivityFromReport(previous, analyzeOpts.RoleHistory)
+		}
+	}
+
+	if len(analyzeClients) > 0 {
+		allowlist, err := checker.ParseClientAllowlist(analyzeClients)
+		if err != nil {
+			return err
+		}
+		analyzeOpts.ClientAllowlist = allowlist
+	}
+
+	if analyzeOpts.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	config, err := pgxpool.ParseConfig(analyzeDsn)
+	if err != nil {
+		return fmt.Errorf("failed to parse DSN: %w", err)
+	}
+	config.MaxConns = int32(analyzeOpts.Concurrency)
+	setSessionTimeouts(config.ConnConfig)
+
+	ctx := cmd.Context()
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
-	defer conn.Close(ctx)
+	defer pool.Close()
+
+	if err := pool.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
 
 	log.Println("Analyzing PostgreSQL configuration...")
 
-	report, err := configcheck.Analyze(ctx, conn)
+	var report *configcheck.Report
+	if analyzeAllDBs {
+		report, err = analyzeAllDatabases(ctx, pool, config)
+	} else {
+		report, err = configcheck.AnalyzePool(ctx, pool, analyzeOpts)
+	}
 	if err != nil {
 		return fmt.Errorf("analysis failed: %w", err)
 	}
 
-	jsonData, err := json.MarshalIndent(report, "", "  ")
+	var output any = report
+	if analyzeFormat == "sarif" {
+		output = sarif.FromReport(report, version)
+	}
+
+	jsonData, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
@@ -63,3 +186,24 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func analyzeAllDatabases(ctx context.Context, pool *pgxpool.Pool, config *pgxpool.Config) (*configcheck.Report, error) {
+	databases, err := checker.ListDatabases(ctx, pool, analyzeInclude, analyzeExclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+	if len(databases) == 0 {
+		return nil, fmt.Errorf("no databases match --include-db/--exclude-db")
+	}
+
+	connect := func(ctx context.Context, database string, maxConns int) (*pgxpool.Pool, error) {
+		c := config.Copy()
+		c.ConnConfig.Database = database
+		c.MaxConns = int32(maxConns)
+		return pgxpool.NewWithConfig(ctx, c)
+	}
+
+	log.Printf("Analyzing %d database(s)...\n", len(databases))
+
+	return configcheck.AnalyzeDatabases(ctx, pool, connect, databases, analyzeOpts)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pg-sec-lab/pkg/checker"
+)
+
+// connect opens a connection with the session timeouts from the global flags
+func connect(ctx context.Context, dsn string) (*pgx.Conn, error) {
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DSN: %w", err)
+	}
+	setSessionTimeouts(config)
+
+	return pgx.ConnectConfig(ctx, config)
+}
+
+// setSessionTimeouts sends statement_timeout and lock_timeout as startup
+// parameters unless the DSN already sets them, and tags the connection with
+// the tool's application_name
+func setSessionTimeouts(config *pgx.ConnConfig) {
+	checker.SetApplicationName(config)
+	setRuntimeParam(config, "statement_timeout", statementTimeout)
+	setRuntimeParam(config, "lock_timeout", lockTimeout)
+}
+
+func setRuntimeParam(config *pgx.ConnConfig, name string, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	if _, ok := config.RuntimeParams[name]; ok {
+		return
+	}
+	config.RuntimeParams[name] = strconv.FormatInt(d.Milliseconds(), 10)
+}
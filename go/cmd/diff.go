@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"pg-sec-lab/internal/diff"
+	"pg-sec-lab/pkg/checker"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffFormat  string
+	diffOutFile string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old.json> <new.json>",
+	Short: "Compare two analyze reports",
+	Long: `Compare two JSON reports produced by analyze and show new and resolved findings,
+role and grant changes, and tables whose RLS flag flipped`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVar(&diffFormat, "format", "text", "output format: text, json or md")
+	diffCmd.Flags().StringVar(&diffOutFile, "out", "", "output file (default: stdout)")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	oldReport, err := checker.LoadReport(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", args[0], err)
+	}
+
+	newReport, err := checker.LoadReport(args[1])
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", args[1], err)
+	}
+
+	result := diff.Compare(oldReport, newReport)
+
+	var output string
+	switch diffFormat {
+	case "text":
+		output = diff.RenderText(result)
+	case "md", "markdown":
+		output = diff.RenderMarkdown(result)
+	case "json":
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		output = string(jsonData) + "\n"
+	default:
+		return fmt.Errorf("unknown format %q (expected text, json or md)", diffFormat)
+	}
+
+	if diffOutFile == "" {
+		fmt.Print(output)
+	} else {
+		if err := os.WriteFile(diffOutFile, []byte(output), 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		fmt.Printf("Diff saved to: %s\n", diffOutFile)
+	}
+
+	return nil
+}
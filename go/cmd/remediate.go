@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"pg-sec-lab/internal/remediate"
+	"pg-sec-lab/pkg/checker"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	remediateInFile   string
+	remediateOutFile  string
+	remediateCodes    []string
+	remediateSafeOnly bool
+)
+
+var remediateCmd = &cobra.Command{
+	Use:   "remediate",
+	Short: "Generate a fix script from an analyze report",
+	Long: `Generate a psql script with the remediation SQL of the findings in an analyze report.
+Every statement is annotated with its finding, explanation and risk notes for review.`,
+	RunE: runRemediate,
+}
+
+func init() {
+	rootCmd.AddCommand(remediateCmd)
+	remediateCmd.Flags().StringVar(&remediateInFile, "in", "", "analyze JSON report (required)")
+	remediateCmd.Flags().StringVar(&remediateOutFile, "out", "", "output file (default: stdout)")
+	remediateCmd.Flags().StringSliceVar(&remediateCodes, "codes", nil, "finding codes to fix, e.g. NO_RLS,BYPASS_RLS (default: all)")
+	remediateCmd.Flags().BoolVar(&remediateSafeOnly, "safe-only", false, "include only fixes marked safe to automate")
+	remediateCmd.MarkFlagRequired("in")
+}
+
+func runRemediate(cmd *cobra.Command, args []string) error {
+	report, err := checker.LoadReport(remediateInFile)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", remediateInFile, err)
+	}
+
+	script, err := remediate.Script(report, remediate.Options{
+		Codes:    remediateCodes,
+		SafeOnly: remediateSafeOnly,
+	})
+	if err != nil {
+		return err
+	}
+
+	if remediateOutFile == "" {
+		fmt.Print(script)
+	} else {
+		if err := os.WriteFile(remediateOutFile, []byte(script), 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		fmt.Printf("Remediation script saved to: %s\n", remediateOutFile)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"pg-sec-lab/internal/report"
+	"pg-sec-lab/pkg/checker"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportInFile   string
+	reportOutFile  string
+	reportFormat   string
+	reportTemplate string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Render an analyze report as HTML or Markdown",
+	Long: `Render a JSON report produced by analyze as a self-contained HTML page or a
+Markdown document with an executive summary. The built-in templates can be
+replaced with --template.`,
+	RunE: runReport,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVar(&reportInFile, "in", "report.json", "input JSON report")
+	reportCmd.Flags().StringVar(&reportOutFile, "out", "", "output file (default: stdout)")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "html", "output format: html or md")
+	reportCmd.Flags().StringVar(&reportTemplate, "template", "", "custom Go template to use instead of the built-in one")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	r, err := checker.LoadReport(reportInFile)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := report.Render(&buf, r, reportFormat, reportTemplate); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+
+	if reportOutFile == "" {
+		fmt.Print(buf.String())
+	} else {
+		if err := os.WriteFile(reportOutFile, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		fmt.Printf("Report rendered to: %s\n", reportOutFile)
+	}
+
+	return nil
+}
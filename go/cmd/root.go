@@ -1,22 +1,59 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
+const version = "1.0.0"
+
+var (
+	timeout          time.Duration
+	statementTimeout time.Duration
+	lockTimeout      time.Duration
+	cancelTimeout    context.CancelFunc = func() {}
+)
+
 var rootCmd = &cobra.Command{
-	Use:   "pg-sec-lab",
-	Short: "PostgreSQL Security Lab - Policy-as-Code for PostgreSQL",
+	Use:     "pg-sec-lab",
+	Version: version,
+	Short:   "PostgreSQL Security Lab - Policy-as-Code for PostgreSQL",
 	Long: `pg-sec-lab is a CLI tool for managing PostgreSQL security through
 declarative policies. It supports role management, RLS policies,
 data masking, and security configuration analysis.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(ctx)
+			cancelTimeout = cancel
+		}
+	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// The first SIGINT or SIGTERM cancels in-flight queries and lets cleanup
+	// run; a second one terminates immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	cancelTimeout()
+	stop()
+
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("%w (--timeout %s exceeded)", err, timeout)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -24,4 +61,7 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "abort the command after this long, e.g. 10m (0 disables)")
+	rootCmd.PersistentFlags().DurationVar(&statementTimeout, "statement-timeout", 60*time.Second, "statement_timeout for every database session (0 keeps the server default)")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", 10*time.Second, "lock_timeout for every database session (0 keeps the server default)")
 }
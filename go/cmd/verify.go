@@ -1,14 +1,12 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"log"
 
 	"pg-sec-lab/internal/policy"
 	"pg-sec-lab/internal/verifier"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/spf13/cobra"
 )
 
@@ -37,8 +35,8 @@ func runVerify(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load policy: %w", err)
 	}
 
-	ctx := context.Background()
-	conn, err := pgx.Connect(ctx, dsn)
+	ctx := cmd.Context()
+	conn, err := connect(ctx, dsn)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"pg-sec-lab/pkg/access"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	whoCanDsn       string
+	whoCanObject    string
+	whoCanPrivilege string
+	whoCanFormat    string
+)
+
+var whoCanCmd = &cobra.Command{
+	Use:   "who-can",
+	Short: "Show which roles can exercise a privilege on an object",
+	Long: `Resolve every access path to a table, view or sequence through role membership,
+PUBLIC, ownership, superuser, predefined roles, views and SECURITY DEFINER
+functions, and explain each path as a chain`,
+	RunE: runWhoCan,
+}
+
+func init() {
+	rootCmd.AddCommand(whoCanCmd)
+	whoCanCmd.Flags().StringVar(&whoCanDsn, "dsn", "", "database connection string (required)")
+	whoCanCmd.Flags().StringVar(&whoCanObject, "object", "", "object name, e.g. public.customers (required)")
+	whoCanCmd.Flags().StringVar(&whoCanPrivilege, "privilege", "SELECT", "privilege to resolve")
+	whoCanCmd.Flags().StringVar(&whoCanFormat, "format", "text", "output format: text or json")
+	whoCanCmd.MarkFlagRequired("dsn")
+	whoCanCmd.MarkFlagRequired("object")
+}
+
+func runWhoCan(cmd *cobra.Command, args []string) error {
+	if whoCanFormat != "text" && whoCanFormat != "json" {
+		return fmt.Errorf("unknown format %q (expected text or json)", whoCanFormat)
+	}
+
+	ctx := cmd.Context()
+	conn, err := connect(ctx, whoCanDsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	result, err := access.Resolve(ctx, conn, whoCanObject, whoCanPrivilege)
+	if err != nil {
+		return err
+	}
+
+	if whoCanFormat == "json" {
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	fmt.Printf("%s on %s (owner %s", result.Privilege, result.Object, result.Owner)
+	if result.RLSEnabled {
+		fmt.Printf(", RLS enabled")
+		if result.RLSForced {
+			fmt.Printf(" and forced")
+		}
+	}
+	fmt.Printf("): %d role(s)\n\n", len(result.Roles))
+
+	for _, ra := range result.Roles {
+		login := ""
+		if ra.Login {
+			login = " (login)"
+		}
+		fmt.Printf("%s%s\n", ra.Role, login)
+		for _, p := range ra.Paths {
+			note := ""
+			switch p.RLS {
+			case access.RLSBypassed:
+				note = "  [bypasses RLS]"
+			case access.RLSEnforced:
+				note = "  [rows filtered by RLS]"
+			}
+			fmt.Printf("  %-10s %s%s\n", p.Kind, p.String(), note)
+		}
+	}
+
+	return nil
+}
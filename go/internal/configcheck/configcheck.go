@@ -6,6 +6,7 @@ import (
 	"pg-sec-lab/pkg/checker"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Re-export types from pkg/checker for backward compatibility
@@ -14,8 +15,27 @@ type RoleInfo = checker.RoleInfo
 type TableInfo = checker.TableInfo
 type Finding = checker.Finding
 type Report = checker.Report
+type Options = checker.Options
+type MaskedColumn = checker.MaskedColumn
+type SettingCheck = checker.SettingCheck
+type ConnectFunc = checker.ConnectFunc
 
 // Analyze delegates to the public checker package
 func Analyze(ctx context.Context, conn *pgx.Conn) (*Report, error) {
 	return checker.Analyze(ctx, conn)
 }
+
+// AnalyzeWithOptions delegates to the public checker package
+func AnalyzeWithOptions(ctx context.Context, conn *pgx.Conn, opts Options) (*Report, error) {
+	return checker.AnalyzeWithOptions(ctx, conn, opts)
+}
+
+// AnalyzePool delegates to the public checker package
+func AnalyzePool(ctx context.Context, pool *pgxpool.Pool, opts Options) (*Report, error) {
+	return checker.AnalyzePool(ctx, pool, opts)
+}
+
+// AnalyzeDatabases delegates to the public checker package
+func AnalyzeDatabases(ctx context.Context, pool *pgxpool.Pool, connect ConnectFunc, databases []string, opts Options) (*Report, error) {
+	return checker.AnalyzeDatabases(ctx, pool, connect, databases, opts)
+}
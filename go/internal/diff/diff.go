@@ -0,0 +1,297 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+
+	"pg-sec-lab/pkg/checker"
+)
+
+type RoleChange struct {
+	Name    string   `json:"name"`
+	Changes []string `json:"changes"`
+}
+
+type GrantChange struct {
+	Database string `json:"database,omitempty"`
+	Role     string `json:"role"`
+	Grant    string `json:"grant"`
+}
+
+type RLSChange struct {
+	Database string `json:"database,omitempty"`
+	Schema   string `json:"schema"`
+	Name     string `json:"name"`
+	Before   bool   `json:"before"`
+	After    bool   `json:"after"`
+}
+
+type Result struct {
+	NewFindings      []checker.Finding `json:"new_findings"`
+	ResolvedFindings []checker.Finding `json:"resolved_findings"`
+	RolesAdded       []string          `json:"roles_added"`
+	RolesRemoved     []string          `json:"roles_removed"`
+	RolesChanged     []RoleChange      `json:"roles_changed"`
+	GrantsAdded      []GrantChange     `json:"grants_added"`
+	GrantsRevoked    []GrantChange     `json:"grants_revoked"`
+	RLSChanged       []RLSChange       `json:"rls_changed"`
+}
+
+// Empty reports whether the two compared reports are equivalent
+func (r *Result) Empty() bool {
+	return len(r.NewFindings) == 0 && len(r.ResolvedFindings) == 0 &&
+		len(r.RolesAdded) == 0 && len(r.RolesRemoved) == 0 && len(r.RolesChanged) == 0 &&
+		len(r.GrantsAdded) == 0 && len(r.GrantsRevoked) == 0 &&
+		len(r.RLSChanged) == 0
+}
+
+// Compare returns the changes needed to get from oldReport to newReport
+func Compare(oldReport, newReport *checker.Report) *Result {
+	result := &Result{
+		NewFindings:      []checker.Finding{},
+		ResolvedFindings: []checker.Finding{},
+		RolesAdded:       []string{},
+		RolesRemoved:     []string{},
+		RolesChanged:     []RoleChange{},
+		GrantsAdded:      []GrantChange{},
+		GrantsRevoked:    []GrantChange{},
+		RLSChanged:       []RLSChange{},
+	}
+
+	compareFindings(result, oldReport.Findings, newReport.Findings)
+	compareRoles(result, oldReport.Roles, newReport.Roles)
+	compareGrants(result, "", oldReport.Roles, newReport.Roles)
+	compareTables(result, "", oldReport.Tables, newReport.Tables)
+	compareDatabases(result, oldReport.Databases, newReport.Databases)
+
+	return result
+}
+
+// findingKey identifies a finding across runs. The message is left out
+// because it carries values that change between runs (durations, PIDs,
+// role lists).
+func findingKey(f checker.Finding) string {
+	return f.Code + "|" + f.Database + "|" + f.ObjectType + "|" + f.Object
+}
+
+func compareFindings(result *Result, oldFindings, newFindings []checker.Finding) {
+	oldByKey := make(map[string][]checker.Finding)
+	for _, f := range oldFindings {
+		oldByKey[findingKey(f)] = append(oldByKey[findingKey(f)], f)
+	}
+	newByKey := make(map[string][]checker.Finding)
+	for _, f := range newFindings {
+		newByKey[findingKey(f)] = append(newByKey[findingKey(f)], f)
+	}
+
+	// Several findings can share a key (e.g. one per privilege on the same
+	// table); only the difference in their number is a change. Findings with
+	// an unchanged message are matched first.
+	for _, f := range newFindings {
+		key := findingKey(f)
+		if _, done := newByKey[key]; !done {
+			continue
+		}
+		added, resolved := unmatched(newByKey[key], oldByKey[key])
+		result.NewFindings = append(result.NewFindings, added...)
+		result.ResolvedFindings = append(result.ResolvedFindings, resolved...)
+		delete(newByKey, key)
+		delete(oldByKey, key)
+	}
+	for _, f := range oldFindings {
+		key := findingKey(f)
+		if _, ok := oldByKey[key]; ok {
+			result.ResolvedFindings = append(result.ResolvedFindings, oldByKey[key]...)
+			delete(oldByKey, key)
+		}
+	}
+}
+
+// unmatched returns the findings of a and b left after pairing them off
+func unmatched(a, b []checker.Finding) ([]checker.Finding, []checker.Finding) {
+	var restA []checker.Finding
+	used := make([]bool, len(b))
+	for _, f := range a {
+		matched := false
+		for j, g := range b {
+			if !used[j] && g.Message == f.Message {
+				used[j], matched = true, true
+				break
+			}
+		}
+		if !matched {
+			restA = append(restA, f)
+		}
+	}
+
+	var restB []checker.Finding
+	for j, g := range b {
+		if !used[j] {
+			restB = append(restB, g)
+		}
+	}
+
+	n := min(len(restA), len(restB))
+	return restA[n:], restB[n:]
+}
+
+func compareRoles(result *Result, oldRoles, newRoles []checker.RoleInfo) {
+	oldByName := make(map[string]checker.RoleInfo)
+	for _, r := range oldRoles {
+		oldByName[r.Name] = r
+	}
+	newByName := make(map[string]checker.RoleInfo)
+	for _, r := range newRoles {
+		newByName[r.Name] = r
+	}
+
+	for _, name := range sortedKeys(newByName) {
+		oldRole, existed := oldByName[name]
+		if !existed {
+			result.RolesAdded = append(result.RolesAdded, name)
+			continue
+		}
+		if changes := roleAttributeChanges(oldRole, newByName[name]); len(changes) > 0 {
+			result.RolesChanged = append(result.RolesChanged, RoleChange{Name: name, Changes: changes})
+		}
+	}
+
+	for _, name := range sortedKeys(oldByName) {
+		if _, exists := newByName[name]; !exists {
+			result.RolesRemoved = append(result.RolesRemoved, name)
+		}
+	}
+}
+
+// compareGrants compares the table and column grants of the roles in one
+// database; database is empty for single-database reports
+func compareGrants(result *Result, database string, oldRoles, newRoles []checker.RoleInfo) {
+	oldGrants := make(map[string][]string)
+	for _, r := range oldRoles {
+		oldGrants[r.Name] = allGrants(r)
+	}
+	newGrants := make(map[string][]string)
+	for _, r := range newRoles {
+		newGrants[r.Name] = allGrants(r)
+	}
+
+	names := sortedKeys(newGrants)
+	for _, name := range sortedKeys(oldGrants) {
+		if _, ok := newGrants[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		added, revoked := compareStrings(oldGrants[name], newGrants[name])
+		for _, g := range added {
+			result.GrantsAdded = append(result.GrantsAdded, GrantChange{Database: database, Role: name, Grant: g})
+		}
+		for _, g := range revoked {
+			result.GrantsRevoked = append(result.GrantsRevoked, GrantChange{Database: database, Role: name, Grant: g})
+		}
+	}
+}
+
+// compareDatabases compares the per-database sections of --all-databases
+// reports, where the top-level roles carry no grants and tables are empty
+func compareDatabases(result *Result, oldDatabases, newDatabases []checker.DatabaseReport) {
+	oldByName := make(map[string]checker.DatabaseReport)
+	for _, db := range oldDatabases {
+		oldByName[db.Name] = db
+	}
+	newByName := make(map[string]checker.DatabaseReport)
+	for _, db := range newDatabases {
+		newByName[db.Name] = db
+	}
+
+	names := sortedKeys(newByName)
+	for _, name := range sortedKeys(oldByName) {
+		if _, ok := newByName[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		oldDB, newDB := oldByName[name], newByName[name]
+		compareGrants(result, name, oldDB.Roles, newDB.Roles)
+		compareTables(result, name, oldDB.Tables, newDB.Tables)
+	}
+}
+
+// allGrants returns table and column grants of a role in one list
+func allGrants(role checker.RoleInfo) []string {
+	grants := append([]string{}, role.Grants...)
+	for _, cg := range role.ColumnGrants {
+		grants = append(grants, cg.String())
+	}
+	return grants
+}
+
+func roleAttributeChanges(oldRole, newRole checker.RoleInfo) []string {
+	var changes []string
+	attr := func(name string, before, after bool) {
+		if before != after {
+			changes = append(changes, fmt.Sprintf("%s: %t -> %t", name, before, after))
+		}
+	}
+
+	attr("login", oldRole.Login, newRole.Login)
+	attr("superuser", oldRole.Superuser, newRole.Superuser)
+	attr("bypassrls", oldRole.BypassRLS, newRole.BypassRLS)
+
+	return changes
+}
+
+func compareTables(result *Result, database string, oldTables, newTables []checker.TableInfo) {
+	oldRLS := make(map[string]bool)
+	for _, t := range oldTables {
+		oldRLS[t.Schema+"."+t.Name] = t.RLSEnabled
+	}
+
+	for _, t := range newTables {
+		before, existed := oldRLS[t.Schema+"."+t.Name]
+		if existed && before != t.RLSEnabled {
+			result.RLSChanged = append(result.RLSChanged, RLSChange{
+				Database: database,
+				Schema:   t.Schema,
+				Name:     t.Name,
+				Before:   before,
+				After:    t.RLSEnabled,
+			})
+		}
+	}
+}
+
+func compareStrings(oldItems, newItems []string) (added, removed []string) {
+	oldSet := make(map[string]bool)
+	for _, s := range oldItems {
+		oldSet[s] = true
+	}
+	newSet := make(map[string]bool)
+	for _, s := range newItems {
+		newSet[s] = true
+	}
+
+	for _, s := range newItems {
+		if !oldSet[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range oldItems {
+		if !newSet[s] {
+			removed = append(removed, s)
+		}
+	}
+
+	return added, removed
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
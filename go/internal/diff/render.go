@@ -0,0 +1,166 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"pg-sec-lab/pkg/checker"
+)
+
+func RenderText(r *Result) string {
+	var sb strings.Builder
+
+	if r.Empty() {
+		sb.WriteString("No changes between reports\n")
+		return sb.String()
+	}
+
+	writeFindingsText(&sb, "New findings", "+", r.NewFindings)
+	writeFindingsText(&sb, "Resolved findings", "-", r.ResolvedFindings)
+
+	writeListText(&sb, "Roles added", "+", r.RolesAdded)
+	writeListText(&sb, "Roles removed", "-", r.RolesRemoved)
+
+	if len(r.RolesChanged) > 0 {
+		sb.WriteString("Roles changed:\n")
+		for _, rc := range r.RolesChanged {
+			sb.WriteString(fmt.Sprintf("  ~ %s (%s)\n", rc.Name, strings.Join(rc.Changes, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	writeGrantsText(&sb, "Grants added", "+", r.GrantsAdded)
+	writeGrantsText(&sb, "Grants revoked", "-", r.GrantsRevoked)
+
+	if len(r.RLSChanged) > 0 {
+		sb.WriteString("RLS changed:\n")
+		for _, t := range r.RLSChanged {
+			sb.WriteString(fmt.Sprintf("  ~ %s.%s%s: %s -> %s\n", t.Schema, t.Name, inDatabase(t.Database), rlsState(t.Before), rlsState(t.After)))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func RenderMarkdown(r *Result) string {
+	var sb strings.Builder
+
+	sb.WriteString("## pg-sec-lab report diff\n\n")
+
+	if r.Empty() {
+		sb.WriteString("No changes between reports.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Change | Count |\n")
+	sb.WriteString("|---|---|\n")
+	sb.WriteString(fmt.Sprintf("| New findings | %d |\n", len(r.NewFindings)))
+	sb.WriteString(fmt.Sprintf("| Resolved findings | %d |\n", len(r.ResolvedFindings)))
+	sb.WriteString(fmt.Sprintf("| Roles added / removed / changed | %d / %d / %d |\n",
+		len(r.RolesAdded), len(r.RolesRemoved), len(r.RolesChanged)))
+	sb.WriteString(fmt.Sprintf("| Grants added / revoked | %d / %d |\n", len(r.GrantsAdded), len(r.GrantsRevoked)))
+	sb.WriteString(fmt.Sprintf("| Tables with RLS changed | %d |\n\n", len(r.RLSChanged)))
+
+	writeFindingsMarkdown(&sb, "New findings", r.NewFindings)
+	writeFindingsMarkdown(&sb, "Resolved findings", r.ResolvedFindings)
+
+	if len(r.RolesAdded) > 0 || len(r.RolesRemoved) > 0 || len(r.RolesChanged) > 0 {
+		sb.WriteString("### Roles\n\n")
+		for _, name := range r.RolesAdded {
+			sb.WriteString(fmt.Sprintf("- ➕ `%s`\n", name))
+		}
+		for _, name := range r.RolesRemoved {
+			sb.WriteString(fmt.Sprintf("- ➖ `%s`\n", name))
+		}
+		for _, rc := range r.RolesChanged {
+			sb.WriteString(fmt.Sprintf("- ✏️ `%s`: %s\n", rc.Name, strings.Join(rc.Changes, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.GrantsAdded) > 0 || len(r.GrantsRevoked) > 0 {
+		sb.WriteString("### Grants\n\n")
+		for _, g := range r.GrantsAdded {
+			sb.WriteString(fmt.Sprintf("- ➕ `%s` to `%s`%s\n", g.Grant, g.Role, inDatabase(g.Database)))
+		}
+		for _, g := range r.GrantsRevoked {
+			sb.WriteString(fmt.Sprintf("- ➖ `%s` from `%s`%s\n", g.Grant, g.Role, inDatabase(g.Database)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.RLSChanged) > 0 {
+		sb.WriteString("### Row Level Security\n\n")
+		for _, t := range r.RLSChanged {
+			sb.WriteString(fmt.Sprintf("- `%s.%s`%s: %s → %s\n", t.Schema, t.Name, inDatabase(t.Database), rlsState(t.Before), rlsState(t.After)))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func writeFindingsText(sb *strings.Builder, title, marker string, findings []checker.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	sb.WriteString(title + ":\n")
+	for _, f := range findings {
+		sb.WriteString(fmt.Sprintf("  %s [%s] %s: %s\n", marker, f.Severity, f.Code, f.Message))
+	}
+	sb.WriteString("\n")
+}
+
+func writeListText(sb *strings.Builder, title, marker string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString(title + ":\n")
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("  %s %s\n", marker, item))
+	}
+	sb.WriteString("\n")
+}
+
+func writeGrantsText(sb *strings.Builder, title, marker string, grants []GrantChange) {
+	if len(grants) == 0 {
+		return
+	}
+	sb.WriteString(title + ":\n")
+	for _, g := range grants {
+		sb.WriteString(fmt.Sprintf("  %s %s: %s%s\n", marker, g.Role, g.Grant, inDatabase(g.Database)))
+	}
+	sb.WriteString("\n")
+}
+
+func writeFindingsMarkdown(sb *strings.Builder, title string, findings []checker.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("### %s\n\n", title))
+	sb.WriteString("| Severity | Code | Message |\n")
+	sb.WriteString("|---|---|---|\n")
+	for _, f := range findings {
+		sb.WriteString(fmt.Sprintf("| %s | `%s` | %s |\n", f.Severity, f.Code, escapeMarkdownCell(f.Message)))
+	}
+	sb.WriteString("\n")
+}
+
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func inDatabase(database string) string {
+	if database == "" {
+		return ""
+	}
+	return " in " + database
+}
+
+func rlsState(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
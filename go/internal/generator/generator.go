@@ -28,6 +28,11 @@ func GenerateSQL(p *policy.Policy) (string, error) {
 	grantsSQL := generateGrants(p)
 	sb.WriteString(grantsSQL)
 
+	if len(p.DefaultPrivileges) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(generateDefaultPrivileges(p))
+	}
+
 	return sb.String(), nil
 }
 
@@ -150,6 +155,56 @@ func generateGrants(p *policy.Policy) string {
 	return sb.String()
 }
 
+func generateDefaultPrivileges(p *policy.Policy) string {
+	var sb strings.Builder
+	sb.WriteString("-- Default privileges\n")
+
+	for _, dp := range p.DefaultPrivileges {
+		sb.WriteString("ALTER DEFAULT PRIVILEGES")
+		if dp.ForRole != "" {
+			sb.WriteString(fmt.Sprintf(" FOR ROLE %s", pqQuoteIdent(dp.ForRole)))
+		}
+		if dp.InSchema != "" {
+			sb.WriteString(fmt.Sprintf(" IN SCHEMA %s", pqQuoteIdent(dp.InSchema)))
+		}
+
+		actions := strings.Join(dp.Actions, ", ")
+		objectType := strings.ToUpper(dp.ObjectType)
+		if dp.GrantTo != "" {
+			sb.WriteString(fmt.Sprintf(" GRANT %s ON %s TO %s;\n", actions, objectType, granteeIdent(dp.GrantTo)))
+		} else {
+			sb.WriteString(fmt.Sprintf(" REVOKE %s ON %s FROM %s;\n", actions, objectType, granteeIdent(dp.RevokeFrom)))
+		}
+	}
+
+	return sb.String()
+}
+
+// RevertDefaultPrivileges returns statements undoing the grants of the given
+// default privileges. Revoke entries are not undone.
+func RevertDefaultPrivileges(dps []policy.DefaultPrivilege) string {
+	var reverted []policy.DefaultPrivilege
+	for _, dp := range dps {
+		if dp.GrantTo == "" {
+			continue
+		}
+		dp.RevokeFrom, dp.GrantTo = dp.GrantTo, ""
+		reverted = append(reverted, dp)
+	}
+	if len(reverted) == 0 {
+		return ""
+	}
+	return generateDefaultPrivileges(&policy.Policy{DefaultPrivileges: reverted})
+}
+
+// granteeIdent quotes a role name but keeps the PUBLIC keyword as is
+func granteeIdent(role string) string {
+	if strings.EqualFold(role, "public") {
+		return "PUBLIC"
+	}
+	return pqQuoteIdent(role)
+}
+
 func splitObject(obj string) (schema, table string) {
 	parts := strings.SplitN(obj, ".", 2)
 	if len(parts) == 2 {
@@ -44,5 +44,22 @@ func validate(p *Policy) error {
 		}
 	}
 
+	for i, dp := range p.DefaultPrivileges {
+		switch dp.ObjectType {
+		case "tables", "sequences", "functions", "routines", "types", "schemas":
+		default:
+			return fmt.Errorf("default_privileges[%d]: unknown object_type %q", i, dp.ObjectType)
+		}
+		if (dp.GrantTo == "") == (dp.RevokeFrom == "") {
+			return fmt.Errorf("default_privileges[%d]: exactly one of grant_to and revoke_from must be set", i)
+		}
+		if len(dp.Actions) == 0 {
+			return fmt.Errorf("default_privileges[%d]: actions are empty", i)
+		}
+		if dp.ObjectType == "schemas" && dp.InSchema != "" {
+			return fmt.Errorf("default_privileges[%d]: in_schema cannot be used with object_type schemas", i)
+		}
+	}
+
 	return nil
 }
@@ -38,9 +38,19 @@ type TablePolicy struct {
 	Masks []MaskRule `yaml:"masks"`
 }
 
+type DefaultPrivilege struct {
+	ForRole    string   `yaml:"for_role"`
+	InSchema   string   `yaml:"in_schema"`
+	ObjectType string   `yaml:"object_type"`
+	GrantTo    string   `yaml:"grant_to"`
+	RevokeFrom string   `yaml:"revoke_from"`
+	Actions    []string `yaml:"actions"`
+}
+
 type Policy struct {
-	Metadata Metadata               `yaml:"metadata"`
-	Tenants  TenantConfig           `yaml:"tenants"`
-	Roles    map[string]Role        `yaml:"roles"`
-	Tables   map[string]TablePolicy `yaml:"tables"`
+	Metadata          Metadata               `yaml:"metadata"`
+	Tenants           TenantConfig           `yaml:"tenants"`
+	Roles             map[string]Role        `yaml:"roles"`
+	Tables            map[string]TablePolicy `yaml:"tables"`
+	DefaultPrivileges []DefaultPrivilege     `yaml:"default_privileges"`
 }
@@ -0,0 +1,185 @@
+package remediate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"pg-sec-lab/pkg/checker"
+)
+
+// Options selects the findings whose fixes go into the script
+type Options struct {
+	// Codes limits the script to these finding codes; empty means all
+	Codes []string
+
+	// SafeOnly leaves out fixes not marked safe to automate
+	SafeOnly bool
+}
+
+// Script builds a psql script with the fixes for the selected findings.
+// Every statement is preceded by its finding, explanation and risk notes for
+// review. Statements run in one transaction per database; ALTER SYSTEM,
+// which cannot run in a transaction, follows it together with a reload and a
+// note on the settings that still need a server restart.
+// Findings without a SQL fix are listed as manual steps at the end.
+func Script(report *checker.Report, opts Options) (string, error) {
+	for _, code := range opts.Codes {
+		if _, ok := checker.LookupRule(code); !ok {
+			return "", fmt.Errorf("unknown finding code %q", code)
+		}
+	}
+
+	findings := append([]checker.Finding{}, report.Findings...)
+	checker.AttachRemediations(findings)
+
+	byDatabase := make(map[string][]checker.Finding)
+	var manual []checker.Finding
+	for _, f := range findings {
+		if len(opts.Codes) > 0 && !contains(opts.Codes, f.Code) {
+			continue
+		}
+		if f.Remediation == nil || f.Remediation.SQL == "" {
+			manual = append(manual, f)
+			continue
+		}
+		if opts.SafeOnly && !f.Remediation.SafeToAutomate {
+			continue
+		}
+		byDatabase[f.Database] = append(byDatabase[f.Database], f)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("-- Remediation script generated by pg-sec-lab\n")
+	if report.Instance.Version != "" {
+		sb.WriteString(fmt.Sprintf("-- Server: %s\n", report.Instance.Version))
+	}
+	if len(opts.Codes) > 0 {
+		sb.WriteString(fmt.Sprintf("-- Codes: %s\n", strings.Join(opts.Codes, ", ")))
+	}
+	if opts.SafeOnly {
+		sb.WriteString("-- Only fixes marked safe to automate are included\n")
+	}
+	sb.WriteString("-- Review every statement and its risk notes, then run:\n")
+	sb.WriteString("--   psql -f <this file>\n")
+	sb.WriteString("\\set ON_ERROR_STOP on\n")
+
+	if len(byDatabase) == 0 {
+		sb.WriteString("\n-- No findings with a SQL fix match the selection\n")
+	}
+
+	for _, database := range sortedDatabases(byDatabase) {
+		writeDatabase(&sb, database, byDatabase[database], report.Instance)
+	}
+
+	if len(manual) > 0 {
+		sb.WriteString("\n-- Manual steps (no SQL fix):\n")
+		for _, f := range manual {
+			sb.WriteString(fmt.Sprintf("--\n-- [%s] %s%s\n", f.Code, databasePrefix(f.Database), f.Message))
+			if f.Remediation != nil {
+				sb.WriteString(fmt.Sprintf("--   %s\n", f.Remediation.Explanation))
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func writeDatabase(sb *strings.Builder, database string, findings []checker.Finding, instance checker.InstanceInfo) {
+	sb.WriteString("\n")
+	switch {
+	case database != "":
+		sb.WriteString(fmt.Sprintf("\\connect %s\n", quoteLiteral(database)))
+	case instance.Database != "":
+		sb.WriteString(fmt.Sprintf("-- Database: %s\n", instance.Database))
+	}
+
+	var inTx, afterTx, restart []string
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		var block strings.Builder
+		block.WriteString(fmt.Sprintf("\n-- [%s] %s: %s\n", f.Code, f.Severity, f.Message))
+		block.WriteString(fmt.Sprintf("-- Fix: %s\n", f.Remediation.Explanation))
+		if f.Remediation.Risk != "" {
+			block.WriteString(fmt.Sprintf("-- Risk: %s\n", f.Remediation.Risk))
+		}
+		block.WriteString(fmt.Sprintf("-- Safe to automate: %s\n", yesno(f.Remediation.SafeToAutomate)))
+		if f.ObjectType == "setting" && contains(instance.RestartSettings, f.Object) {
+			block.WriteString("-- Takes effect only after a server restart, not on reload\n")
+			if !contains(restart, f.Object) {
+				restart = append(restart, f.Object)
+			}
+		}
+
+		sql := strings.TrimSpace(f.Remediation.SQL)
+		if seen[sql] {
+			block.WriteString("-- (same statement as above)\n")
+		} else {
+			seen[sql] = true
+			block.WriteString(sql + "\n")
+		}
+
+		if strings.HasPrefix(sql, "ALTER SYSTEM") {
+			afterTx = append(afterTx, block.String())
+		} else {
+			inTx = append(inTx, block.String())
+		}
+	}
+
+	if len(inTx) > 0 {
+		sb.WriteString("BEGIN;\n")
+		for _, block := range inTx {
+			sb.WriteString(block)
+		}
+		sb.WriteString("\nCOMMIT;\n")
+	}
+
+	if len(afterTx) > 0 {
+		sb.WriteString("\n-- ALTER SYSTEM cannot run inside a transaction block\n")
+		for _, block := range afterTx {
+			sb.WriteString(block)
+		}
+		sb.WriteString("\nSELECT pg_reload_conf();\n")
+		if len(restart) > 0 {
+			sb.WriteString(fmt.Sprintf("-- Restart the server to apply: %s\n", strings.Join(restart, ", ")))
+		}
+	}
+}
+
+// sortedDatabases orders databases by name. Findings without a database (the
+// connected database and the cluster) have the empty name and come first.
+func sortedDatabases(m map[string][]checker.Finding) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func databasePrefix(database string) string {
+	if database == "" {
+		return ""
+	}
+	return database + ": "
+}
+
+func quoteLiteral(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
+
+func yesno(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func contains(list []string, item string) bool {
+	for _, v := range list {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package remediate
+
+import (
+	"strings"
+	"testing"
+
+	"pg-sec-lab/pkg/checker"
+)
+
+func testReport() *checker.Report {
+	return &checker.Report{
+		Instance: checker.InstanceInfo{
+			Database:        "postgres",
+			RestartSettings: []string{"shared_preload_libraries"},
+		},
+		Findings: []checker.Finding{
+			{
+				Severity:    "high",
+				Code:        "PUBLIC_SCHEMA_CREATE",
+				Message:     "PUBLIC can create objects in schema public",
+				ObjectType:  "schema",
+				Object:      "public",
+				Remediation: &checker.Remediation{SQL: "REVOKE CREATE ON SCHEMA public FROM PUBLIC;"},
+			},
+			{
+				Severity:    "high",
+				Code:        "SUPERUSER_LOGIN",
+				Message:     "Login role app is a superuser",
+				ObjectType:  "role",
+				Object:      "app",
+				Remediation: &checker.Remediation{SQL: `ALTER ROLE "app" NOSUPERUSER;`},
+			},
+			{
+				Severity:    "warning",
+				Code:        "SETTING_MISMATCH",
+				Message:     "Setting shared_preload_libraries is \"\"",
+				ObjectType:  "setting",
+				Object:      "shared_preload_libraries",
+				Remediation: &checker.Remediation{SQL: "ALTER SYSTEM SET shared_preload_libraries = 'pgaudit';"},
+			},
+			{
+				Severity:    "high",
+				Code:        "PUBLIC_SCHEMA_CREATE",
+				Message:     "PUBLIC can create objects in schema public of app",
+				Database:    "app",
+				ObjectType:  "schema",
+				Object:      "public",
+				Remediation: &checker.Remediation{SQL: "REVOKE CREATE ON SCHEMA public FROM PUBLIC;"},
+			},
+			{
+				Severity:    "high",
+				Code:        "PUBLIC_SCHEMA_CREATE",
+				Message:     "PUBLIC can create objects in schema public of app (again)",
+				Database:    "app",
+				ObjectType:  "schema",
+				Object:      "public",
+				Remediation: &checker.Remediation{SQL: "REVOKE CREATE ON SCHEMA public FROM PUBLIC;"},
+			},
+			{
+				Severity:   "high",
+				Code:       "HBA_TRUST",
+				Message:    "pg_hba.conf line 3 uses trust",
+				ObjectType: "hba_rule",
+				Object:     "3",
+			},
+		},
+	}
+}
+
+func TestScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+		// ordered must appear in this order, absent must not appear
+		ordered []string
+		absent  []string
+	}{
+		{
+			name: "all findings",
+			ordered: []string{
+				"-- Database: postgres",
+				"BEGIN;",
+				"REVOKE CREATE ON SCHEMA public FROM PUBLIC;",
+				`ALTER ROLE "app" NOSUPERUSER;`,
+				"COMMIT;",
+				"-- Takes effect only after a server restart",
+				"ALTER SYSTEM SET shared_preload_libraries = 'pgaudit';",
+				"SELECT pg_reload_conf();",
+				"-- Restart the server to apply: shared_preload_libraries",
+				`\connect 'app'`,
+				"BEGIN;",
+				"REVOKE CREATE ON SCHEMA public FROM PUBLIC;",
+				"-- (same statement as above)",
+				"COMMIT;",
+				"-- Manual steps (no SQL fix):",
+				"[HBA_TRUST]",
+			},
+		},
+		{
+			name:    "codes filter",
+			opts:    Options{Codes: []string{"SUPERUSER_LOGIN"}},
+			ordered: []string{"-- Codes: SUPERUSER_LOGIN", "BEGIN;", `ALTER ROLE "app" NOSUPERUSER;`, "COMMIT;"},
+			absent:  []string{"REVOKE CREATE", "ALTER SYSTEM", `\connect`, "[HBA_TRUST]"},
+		},
+		{
+			name:    "unknown code",
+			opts:    Options{Codes: []string{"NO_SUCH_CODE"}},
+			wantErr: true,
+		},
+		{
+			name:    "safe only",
+			opts:    Options{SafeOnly: true},
+			ordered: []string{"-- Only fixes marked safe to automate are included", "REVOKE CREATE ON SCHEMA public FROM PUBLIC;"},
+			absent:  []string{"NOSUPERUSER", "ALTER SYSTEM"},
+		},
+		{
+			name:    "nothing selected",
+			opts:    Options{Codes: []string{"RLS_NOT_FORCED"}},
+			ordered: []string{"-- No findings with a SQL fix match the selection"},
+			absent:  []string{"BEGIN;"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := Script(testReport(), tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Script() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Script() error: %v", err)
+			}
+
+			rest := script
+			for _, want := range tt.ordered {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("script lacks %q after the previous lines:\n%s", want, script)
+				}
+				rest = rest[i+len(want):]
+			}
+			for _, s := range tt.absent {
+				if strings.Contains(script, s) {
+					t.Errorf("script contains %q:\n%s", s, script)
+				}
+			}
+		})
+	}
+}
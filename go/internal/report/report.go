@@ -0,0 +1,222 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"pg-sec-lab/pkg/checker"
+)
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+// severityOrder lists severities from most to least urgent
+var severityOrder = []string{"critical", "high", "warning", "medium", "low", "info"}
+
+type SeverityCount struct {
+	Severity string
+	Count    int
+}
+
+type FindingGroup struct {
+	Severity string
+	Findings []checker.Finding
+}
+
+type Summary struct {
+	RiskLevel      string
+	TotalFindings  int
+	BySeverity     []SeverityCount
+	Roles          int
+	LoginRoles     int
+	Superusers     int
+	BypassRLSRoles int
+	Tables         int
+	TablesWithRLS  int
+	Grants         int
+}
+
+// View is the data passed to report templates
+type View struct {
+	Report      *checker.Report
+	GeneratedAt string
+	Summary     Summary
+	Groups      []FindingGroup
+}
+
+func NewView(r *checker.Report) *View {
+	return &View{
+		Report:      r,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Summary:     summarize(r),
+		Groups:      groupFindings(r.Findings),
+	}
+}
+
+// Render writes the report in the given format ("html" or "md"). When
+// templatePath is not empty it is used instead of the built-in template.
+func Render(w io.Writer, r *checker.Report, format, templatePath string) error {
+	var name string
+	switch format {
+	case "html":
+		name = "report.html.tmpl"
+	case "md", "markdown":
+		format = "md"
+		name = "report.md.tmpl"
+	default:
+		return fmt.Errorf("unknown format %q (expected html or md)", format)
+	}
+
+	src, err := loadTemplate(name, templatePath)
+	if err != nil {
+		return err
+	}
+
+	view := NewView(r)
+
+	if format == "html" {
+		tmpl, err := htmltemplate.New(name).Funcs(htmltemplate.FuncMap(funcs)).Parse(src)
+		if err != nil {
+			return fmt.Errorf("failed to parse template: %w", err)
+		}
+		return tmpl.Execute(w, view)
+	}
+
+	tmpl, err := texttemplate.New(name).Funcs(funcs).Parse(src)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl.Execute(w, view)
+}
+
+func loadTemplate(name, templatePath string) (string, error) {
+	if templatePath != "" {
+		data, err := os.ReadFile(templatePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template: %w", err)
+		}
+		return string(data), nil
+	}
+
+	data, err := templatesFS.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read built-in template: %w", err)
+	}
+	return string(data), nil
+}
+
+var funcs = texttemplate.FuncMap{
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+	"yesno": func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	},
+	"mdcell": func(s string) string {
+		return strings.ReplaceAll(s, "|", `\|`)
+	},
+	"allgrants": func(r checker.RoleInfo) []string {
+		grants := append([]string{}, r.Grants...)
+		for _, cg := range r.ColumnGrants {
+			grants = append(grants, cg.String())
+		}
+		return grants
+	},
+}
+
+func summarize(r *checker.Report) Summary {
+	s := Summary{
+		TotalFindings: len(r.Findings),
+		Roles:         len(r.Roles),
+	}
+
+	for _, role := range r.Roles {
+		if role.Login {
+			s.LoginRoles++
+		}
+		if role.Superuser {
+			s.Superusers++
+		}
+		if role.BypassRLS {
+			s.BypassRLSRoles++
+		}
+	}
+
+	// Multi-database reports keep grants and tables in their database
+	// sections; the top-level lists are empty there
+	roles, tables := [][]checker.RoleInfo{r.Roles}, [][]checker.TableInfo{r.Tables}
+	for _, db := range r.Databases {
+		roles = append(roles, db.Roles)
+		tables = append(tables, db.Tables)
+	}
+
+	for _, list := range roles {
+		for _, role := range list {
+			s.Grants += len(role.Grants) + len(role.ColumnGrants)
+		}
+	}
+
+	for _, list := range tables {
+		s.Tables += len(list)
+		for _, t := range list {
+			if t.RLSEnabled {
+				s.TablesWithRLS++
+			}
+		}
+	}
+
+	for _, g := range groupFindings(r.Findings) {
+		s.BySeverity = append(s.BySeverity, SeverityCount{Severity: g.Severity, Count: len(g.Findings)})
+	}
+
+	s.RiskLevel = "low"
+	if len(s.BySeverity) > 0 {
+		switch s.BySeverity[0].Severity {
+		case "critical":
+			s.RiskLevel = "critical"
+		case "high":
+			s.RiskLevel = "high"
+		case "warning", "medium":
+			s.RiskLevel = "medium"
+		}
+	}
+
+	return s
+}
+
+func groupFindings(findings []checker.Finding) []FindingGroup {
+	bySeverity := make(map[string][]checker.Finding)
+	for _, f := range findings {
+		sev := strings.ToLower(f.Severity)
+		bySeverity[sev] = append(bySeverity[sev], f)
+	}
+
+	var groups []FindingGroup
+	for _, sev := range severityOrder {
+		if fs, ok := bySeverity[sev]; ok {
+			groups = append(groups, FindingGroup{Severity: sev, Findings: fs})
+			delete(bySeverity, sev)
+		}
+	}
+
+	// Severities outside the known order go last, alphabetically
+	var rest []string
+	for sev := range bySeverity {
+		rest = append(rest, sev)
+	}
+	sort.Strings(rest)
+	for _, sev := range rest {
+		groups = append(groups, FindingGroup{Severity: sev, Findings: bySeverity[sev]})
+	}
+
+	return groups
+}
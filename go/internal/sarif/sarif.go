@@ -0,0 +1,182 @@
+package sarif
+
+import (
+	"strings"
+
+	"pg-sec-lab/pkg/checker"
+)
+
+const (
+	schemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	version   = "2.1.0"
+	toolName  = "pg-sec-lab"
+	toolURI   = "https://github.com/izvenyaisya/pg-sec-lab"
+)
+
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string                `json:"name"`
+	Version        string                `json:"version,omitempty"`
+	InformationURI string                `json:"informationUri,omitempty"`
+	Rules          []ReportingDescriptor `json:"rules"`
+}
+
+type ReportingDescriptor struct {
+	ID                   string                  `json:"id"`
+	Name                 string                  `json:"name,omitempty"`
+	ShortDescription     *Message                `json:"shortDescription,omitempty"`
+	FullDescription      *Message                `json:"fullDescription,omitempty"`
+	DefaultConfiguration *ReportingConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]any          `json:"properties,omitempty"`
+}
+
+type ReportingConfiguration struct {
+	Level string `json:"level"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type Result struct {
+	RuleID     string         `json:"ruleId"`
+	RuleIndex  int            `json:"ruleIndex"`
+	Level      string         `json:"level"`
+	Message    Message        `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+type Location struct {
+	LogicalLocations []LogicalLocation `json:"logicalLocations"`
+}
+
+type LogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// FromReport converts an analyze report into a SARIF 2.1.0 log with one run
+func FromReport(report *checker.Report, toolVersion string) *Log {
+	driver := Driver{
+		Name:           toolName,
+		Version:        toolVersion,
+		InformationURI: toolURI,
+		Rules:          []ReportingDescriptor{},
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, rule := range checker.Rules() {
+		ruleIndex[rule.Code] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, descriptorFor(rule))
+	}
+
+	results := []Result{}
+	for _, f := range report.Findings {
+		idx, ok := ruleIndex[f.Code]
+		if !ok {
+			// Findings from reports made by newer versions may carry codes
+			// this build does not know; describe them from the finding itself
+			idx = len(driver.Rules)
+			ruleIndex[f.Code] = idx
+			driver.Rules = append(driver.Rules, descriptorFor(checker.Rule{
+				Code:     f.Code,
+				Title:    f.Code,
+				Severity: f.Severity,
+			}))
+		}
+
+		result := Result{
+			RuleID:    f.Code,
+			RuleIndex: idx,
+			Level:     Level(f.Severity),
+			Message:   Message{Text: f.Message},
+			Properties: map[string]any{
+				"severity": f.Severity,
+			},
+		}
+		database := report.Instance.Database
+		if f.Database != "" {
+			database = f.Database
+		}
+		if loc, ok := logicalLocation(database, f); ok {
+			result.Locations = []Location{{LogicalLocations: []LogicalLocation{loc}}}
+		}
+		results = append(results, result)
+	}
+
+	return &Log{
+		Schema:  schemaURI,
+		Version: version,
+		Runs: []Run{{
+			Tool:    Tool{Driver: driver},
+			Results: results,
+		}},
+	}
+}
+
+// Level maps pg-sec-lab severities onto SARIF result levels
+func Level(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "high", "error":
+		return "error"
+	case "warning", "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func descriptorFor(rule checker.Rule) ReportingDescriptor {
+	d := ReportingDescriptor{
+		ID:                   rule.Code,
+		Name:                 rule.Title,
+		ShortDescription:     &Message{Text: rule.Title},
+		DefaultConfiguration: &ReportingConfiguration{Level: Level(rule.Severity)},
+		Properties: map[string]any{
+			"severity": rule.Severity,
+			"tags":     []string{"security", "postgresql"},
+		},
+	}
+	if rule.Description != "" {
+		d.FullDescription = &Message{Text: rule.Description}
+	}
+	return d
+}
+
+// logicalLocation builds a database/schema/object path for the finding
+func logicalLocation(database string, f checker.Finding) (LogicalLocation, bool) {
+	if f.Object == "" {
+		if database == "" {
+			return LogicalLocation{}, false
+		}
+		return LogicalLocation{Name: database, FullyQualifiedName: database, Kind: "database"}, true
+	}
+
+	parts := []string{}
+	if database != "" {
+		parts = append(parts, database)
+	}
+	parts = append(parts, strings.SplitN(f.Object, ".", 2)...)
+
+	return LogicalLocation{
+		Name:               parts[len(parts)-1],
+		FullyQualifiedName: strings.Join(parts, "/"),
+		Kind:               f.ObjectType,
+	}, true
+}
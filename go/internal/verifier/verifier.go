@@ -24,10 +24,8 @@ func Verify(ctx context.Context, p *policy.Policy, conn *pgx.Conn) error {
 		return fmt.Errorf("failed to create test schema: %w", err)
 	}
 
-	defer func() {
-		log.Printf("Cleaning up test schema: %s\n", testSchema)
-		conn.Exec(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", testSchema))
-	}()
+	var revertSQL string
+	defer func() { cleanup(ctx, conn, testSchema, revertSQL) }()
 
 	if _, err := conn.Exec(ctx, fmt.Sprintf("SET search_path TO %s", testSchema)); err != nil {
 		return fmt.Errorf("failed to set search_path: %w", err)
@@ -44,7 +42,10 @@ func Verify(ctx context.Context, p *policy.Policy, conn *pgx.Conn) error {
 		return fmt.Errorf("failed to insert test data: %w", err)
 	}
 
-	sql, err := generator.GenerateSQL(p)
+	scoped := *p
+	scoped.DefaultPrivileges = scopeDefaultPrivileges(p.DefaultPrivileges, testSchema)
+
+	sql, err := generator.GenerateSQL(&scoped)
 	if err != nil {
 		return fmt.Errorf("failed to generate SQL: %w", err)
 	}
@@ -56,6 +57,7 @@ func Verify(ctx context.Context, p *policy.Policy, conn *pgx.Conn) error {
 	if _, err := conn.Exec(ctx, sql); err != nil {
 		return fmt.Errorf("failed to apply policies: %w", err)
 	}
+	revertSQL = generator.RevertDefaultPrivileges(scoped.DefaultPrivileges)
 
 	if err := verifyRLS(ctx, conn, p, testSchema); err != nil {
 		return fmt.Errorf("RLS verification failed: %w", err)
@@ -66,6 +68,56 @@ func Verify(ctx context.Context, p *policy.Policy, conn *pgx.Conn) error {
 	return nil
 }
 
+// scopeDefaultPrivileges moves default privileges for the public schema to
+// the test schema. The others would change the defaults of the real
+// database, which dropping the test schema does not undo, so they are skipped.
+func scopeDefaultPrivileges(dps []policy.DefaultPrivilege, testSchema string) []policy.DefaultPrivilege {
+	var scoped []policy.DefaultPrivilege
+	for _, dp := range dps {
+		if dp.InSchema != "public" {
+			log.Printf("Skipping default privileges on %s outside the public schema\n", dp.ObjectType)
+			continue
+		}
+		dp.InSchema = testSchema
+		scoped = append(scoped, dp)
+	}
+	return scoped
+}
+
+// cleanupTimeout bounds dropping the test schema after Verify returns
+const cleanupTimeout = 30 * time.Second
+
+// cleanup reverts the applied default privileges and drops the test schema
+// even when ctx was cancelled or timed out. A query interrupted by
+// cancellation closes the connection, so cleanup then reconnects with the
+// same settings.
+func cleanup(ctx context.Context, conn *pgx.Conn, testSchema, revertSQL string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+
+	log.Printf("Cleaning up test schema: %s\n", testSchema)
+
+	if conn.IsClosed() {
+		fresh, err := pgx.ConnectConfig(ctx, conn.Config())
+		if err != nil {
+			log.Printf("⚠️  Failed to reconnect to drop test schema %s: %v\n", testSchema, err)
+			return
+		}
+		defer fresh.Close(ctx)
+		conn = fresh
+	}
+
+	if revertSQL != "" {
+		if _, err := conn.Exec(ctx, revertSQL); err != nil {
+			log.Printf("⚠️  Failed to revert default privileges in %s: %v\n", testSchema, err)
+		}
+	}
+
+	if _, err := conn.Exec(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", testSchema)); err != nil {
+		log.Printf("⚠️  Failed to drop test schema %s: %v\n", testSchema, err)
+	}
+}
+
 func createTestTables(ctx context.Context, conn *pgx.Conn) error {
 	schema := `
 		CREATE TABLE customers (
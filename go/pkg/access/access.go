@@ -0,0 +1,492 @@
+// Package access answers "who can do X on this object?" by resolving grants
+// through role membership, PUBLIC, ownership, superuser, predefined roles,
+// views and SECURITY DEFINER functions.
+package access
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"pg-sec-lab/pkg/checker"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Path kinds
+const (
+	KindSuperuser  = "superuser"
+	KindOwner      = "owner"
+	KindGrant      = "grant"
+	KindPublic     = "public"
+	KindPredefined = "predefined"
+	KindView       = "view"
+	KindFunction   = "function"
+)
+
+// RLS states of a path
+const (
+	RLSBypassed = "bypassed"
+	RLSEnforced = "enforced"
+)
+
+var tablePrivileges = map[string]bool{
+	"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true,
+	"TRUNCATE": true, "REFERENCES": true, "TRIGGER": true, "MAINTAIN": true,
+}
+
+// Path is one way a role obtains the privilege. Chain starts with the role
+// itself and ends with the step that actually grants the privilege.
+type Path struct {
+	Kind            string   `json:"kind"`
+	Chain           []string `json:"chain"`
+	RequiresSetRole bool     `json:"requires_set_role"`
+	RLS             string   `json:"rls,omitempty"`
+}
+
+func (p Path) String() string {
+	return strings.Join(p.Chain, " -> ")
+}
+
+type RoleAccess struct {
+	Role  string `json:"role"`
+	Login bool   `json:"login"`
+	Paths []Path `json:"paths"`
+}
+
+type Result struct {
+	Object     string       `json:"object"`
+	Privilege  string       `json:"privilege"`
+	Owner      string       `json:"owner"`
+	RLSEnabled bool         `json:"rls_enabled"`
+	RLSForced  bool         `json:"rls_forced"`
+	Roles      []RoleAccess `json:"roles"`
+}
+
+type role struct {
+	name      string
+	login     bool
+	superuser bool
+	bypassRLS bool
+}
+
+// holder is a role that holds the privilege on its own. actsAs is the role
+// whose identity reaches the table (view or function owner); empty means the
+// session role itself.
+type holder struct {
+	role   string
+	kind   string
+	step   string
+	actsAs string
+}
+
+type target struct {
+	oid        uint32
+	name       string
+	owner      string
+	rlsEnabled bool
+	rlsForced  bool
+}
+
+// Resolve lists every non-predefined role that can exercise privilege on the
+// table, view or sequence named by object, with the chains that lead there
+func Resolve(ctx context.Context, conn *pgx.Conn, object, privilege string) (*Result, error) {
+	privilege = strings.ToUpper(strings.TrimSpace(privilege))
+	if !tablePrivileges[privilege] {
+		return nil, fmt.Errorf("unsupported privilege %q", privilege)
+	}
+
+	t, err := loadTarget(ctx, conn, object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve object %s: %w", object, err)
+	}
+
+	roles, err := loadRoles(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+
+	graph, err := checker.LoadRoleGraph(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load role memberships: %w", err)
+	}
+
+	holders, err := loadHolders(ctx, conn, t, privilege, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &resolver{target: t, roles: roles, graph: graph}
+
+	result := &Result{
+		Object:     t.name,
+		Privilege:  privilege,
+		Owner:      t.owner,
+		RLSEnabled: t.rlsEnabled,
+		RLSForced:  t.rlsForced,
+		Roles:      []RoleAccess{},
+	}
+
+	names := make([]string, 0, len(roles))
+	for name := range roles {
+		if !strings.HasPrefix(name, "pg_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		paths := r.paths(name, holders)
+		if len(paths) == 0 {
+			continue
+		}
+		result.Roles = append(result.Roles, RoleAccess{
+			Role:  name,
+			Login: roles[name].login,
+			Paths: paths,
+		})
+	}
+
+	return result, nil
+}
+
+func loadTarget(ctx context.Context, conn *pgx.Conn, object string) (target, error) {
+	var t target
+	err := conn.QueryRow(ctx, `
+		SELECT
+			c.oid,
+			quote_ident(n.nspname) || '.' || quote_ident(c.relname),
+			pg_get_userbyid(c.relowner),
+			c.relrowsecurity,
+			c.relforcerowsecurity
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.oid = $1::text::regclass
+	`, object).Scan(&t.oid, &t.name, &t.owner, &t.rlsEnabled, &t.rlsForced)
+	return t, err
+}
+
+func loadRoles(ctx context.Context, conn *pgx.Conn) (map[string]role, error) {
+	rows, err := conn.Query(ctx, `SELECT rolname, rolcanlogin, rolsuper, rolbypassrls FROM pg_roles`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make(map[string]role)
+	for rows.Next() {
+		var r role
+		if err := rows.Scan(&r.name, &r.login, &r.superuser, &r.bypassRLS); err != nil {
+			return nil, err
+		}
+		roles[r.name] = r
+	}
+
+	return roles, rows.Err()
+}
+
+func loadHolders(ctx context.Context, conn *pgx.Conn, t target, privilege string, roles map[string]role) ([]holder, error) {
+	var holders []holder
+
+	var superusers []string
+	for name, r := range roles {
+		if r.superuser {
+			superusers = append(superusers, name)
+		}
+	}
+	sort.Strings(superusers)
+	for _, name := range superusers {
+		holders = append(holders, holder{role: name, kind: KindSuperuser, step: "SUPERUSER"})
+	}
+
+	holders = append(holders, holder{
+		role: t.owner,
+		kind: KindOwner,
+		step: fmt.Sprintf("owner of %s", t.name),
+	})
+
+	grants, err := aclGrantees(ctx, conn, `
+		SELECT CASE WHEN a.grantee = 0 THEN 'public' ELSE pg_get_userbyid(a.grantee) END
+		FROM pg_class c,
+			aclexplode(coalesce(c.relacl, acldefault(CASE WHEN c.relkind = 'S' THEN 's' ELSE 'r' END::"char", c.relowner))) a
+		WHERE c.oid = $1 AND a.privilege_type = $2
+	`, t.oid, privilege)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table ACL: %w", err)
+	}
+	for _, grantee := range grants {
+		if grantee == t.owner {
+			continue
+		}
+		kind := KindGrant
+		if grantee == "public" {
+			kind = KindPublic
+		}
+		holders = append(holders, holder{
+			role: grantee,
+			kind: kind,
+			step: fmt.Sprintf("GRANT %s ON %s", privilege, t.name),
+		})
+	}
+
+	switch privilege {
+	case "SELECT":
+		holders = append(holders, holder{role: "pg_read_all_data", kind: KindPredefined, step: "predefined role reads all data"})
+	case "INSERT", "UPDATE", "DELETE":
+		holders = append(holders, holder{role: "pg_write_all_data", kind: KindPredefined, step: "predefined role writes all data"})
+	}
+
+	if privilege == "SELECT" {
+		viewHolders, err := loadViewHolders(ctx, conn, t)
+		if err != nil {
+			return nil, err
+		}
+		holders = append(holders, viewHolders...)
+	}
+
+	funcHolders, err := loadFunctionHolders(ctx, conn, t, privilege)
+	if err != nil {
+		return nil, err
+	}
+	holders = append(holders, funcHolders...)
+
+	return holders, nil
+}
+
+// loadViewHolders finds views reading the table whose owner can read it;
+// unless security_invoker is set, the view accesses the table as its owner
+func loadViewHolders(ctx context.Context, conn *pgx.Conn, t target) ([]holder, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT DISTINCT
+			v.oid,
+			quote_ident(vn.nspname) || '.' || quote_ident(v.relname),
+			pg_get_userbyid(v.relowner)
+		FROM pg_depend d
+		JOIN pg_rewrite rw ON rw.oid = d.objid
+		JOIN pg_class v ON v.oid = rw.ev_class
+		JOIN pg_namespace vn ON vn.oid = v.relnamespace
+		WHERE d.classid = 'pg_rewrite'::regclass
+		  AND d.refclassid = 'pg_class'::regclass
+		  AND d.refobjid = $1
+		  AND v.oid <> $1
+		  AND NOT coalesce(v.reloptions::text[] && ARRAY['security_invoker=true', 'security_invoker=on', 'security_invoker=1'], false)
+		  AND has_table_privilege(v.relowner, $1::oid, 'SELECT')
+	`, t.oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find dependent views: %w", err)
+	}
+
+	type view struct {
+		oid   uint32
+		name  string
+		owner string
+	}
+	var views []view
+	for rows.Next() {
+		var v view
+		if err := rows.Scan(&v.oid, &v.name, &v.owner); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		views = append(views, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var holders []holder
+	for _, v := range views {
+		grantees, err := aclGrantees(ctx, conn, `
+			SELECT CASE WHEN a.grantee = 0 THEN 'public' ELSE pg_get_userbyid(a.grantee) END
+			FROM pg_class c,
+				aclexplode(coalesce(c.relacl, acldefault('r', c.relowner))) a
+			WHERE c.oid = $1 AND a.privilege_type = $2
+		`, v.oid, "SELECT")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read view ACL: %w", err)
+		}
+		for _, grantee := range grantees {
+			holders = append(holders, holder{
+				role:   grantee,
+				kind:   KindView,
+				step:   fmt.Sprintf("SELECT ON view %s (runs as owner %s)", v.name, v.owner),
+				actsAs: v.owner,
+			})
+		}
+	}
+
+	return holders, nil
+}
+
+// loadFunctionHolders finds SECURITY DEFINER functions whose body mentions
+// the table and whose owner holds the privilege. Function bodies are not
+// dependency-tracked, so these paths are reported as possible, not certain.
+func loadFunctionHolders(ctx context.Context, conn *pgx.Conn, t target, privilege string) ([]holder, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT p.oid, p.oid::regprocedure::text, pg_get_userbyid(p.proowner)
+		FROM pg_proc p, pg_class c
+		WHERE c.oid = $1
+		  AND p.prosecdef
+		  AND strpos(lower(p.prosrc), lower(c.relname)) > 0
+		  AND has_table_privilege(p.proowner, c.oid, $2)
+	`, t.oid, privilege)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find SECURITY DEFINER functions: %w", err)
+	}
+
+	type function struct {
+		oid       uint32
+		signature string
+		owner     string
+	}
+	var functions []function
+	for rows.Next() {
+		var f function
+		if err := rows.Scan(&f.oid, &f.signature, &f.owner); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		functions = append(functions, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var holders []holder
+	for _, f := range functions {
+		grantees, err := aclGrantees(ctx, conn, `
+			SELECT CASE WHEN a.grantee = 0 THEN 'public' ELSE pg_get_userbyid(a.grantee) END
+			FROM pg_proc p,
+				aclexplode(coalesce(p.proacl, acldefault('f', p.proowner))) a
+			WHERE p.oid = $1 AND a.privilege_type = $2
+		`, f.oid, "EXECUTE")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read function ACL: %w", err)
+		}
+		for _, grantee := range grantees {
+			holders = append(holders, holder{
+				role:   grantee,
+				kind:   KindFunction,
+				step:   fmt.Sprintf("EXECUTE ON SECURITY DEFINER function %s (runs as owner %s, body references the table)", f.signature, f.owner),
+				actsAs: f.owner,
+			})
+		}
+	}
+
+	return holders, nil
+}
+
+func aclGrantees(ctx context.Context, conn *pgx.Conn, query string, oid uint32, privilege string) ([]string, error) {
+	rows, err := conn.Query(ctx, query, oid, privilege)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grantees []string
+	for rows.Next() {
+		var g string
+		if err := rows.Scan(&g); err != nil {
+			return nil, err
+		}
+		grantees = append(grantees, g)
+	}
+
+	return grantees, rows.Err()
+}
+
+type resolver struct {
+	target target
+	roles  map[string]role
+	graph  *checker.RoleGraph
+}
+
+func (r *resolver) paths(name string, holders []holder) []Path {
+	inherited := r.graph.Paths(name, true)
+	viaSet := r.graph.Paths(name, false)
+
+	var paths []Path
+	seen := make(map[string]bool)
+
+	for _, h := range holders {
+		var chain []string
+		requiresSet := false
+
+		switch {
+		case h.role == "public":
+			chain = []string{name, "PUBLIC"}
+		case h.role == name:
+			chain = []string{name}
+		case inherited[h.role] != nil && h.kind != KindSuperuser:
+			chain = append([]string{}, inherited[h.role]...)
+		case viaSet[h.role] != nil:
+			// SUPERUSER is an attribute and is never inherited; a member of a
+			// superuser role only gets it after SET ROLE
+			chain = append([]string{}, viaSet[h.role]...)
+			requiresSet = true
+		default:
+			continue
+		}
+		chain = append(chain, h.step)
+
+		p := Path{
+			Kind:            h.kind,
+			Chain:           chain,
+			RequiresSetRole: requiresSet,
+		}
+		if requiresSet {
+			p.Chain[len(p.Chain)-2] = "SET ROLE " + p.Chain[len(p.Chain)-2]
+		}
+
+		// RLS is checked against current_user: the session role, the role
+		// switched to with SET ROLE, or the owner of a view/function
+		subject := name
+		switch {
+		case h.actsAs != "":
+			subject = h.actsAs
+		case requiresSet:
+			subject = h.role
+		}
+		p.RLS = r.rlsState(subject)
+
+		key := p.Kind + "|" + p.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		paths = append(paths, p)
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		return len(paths[i].Chain) < len(paths[j].Chain)
+	})
+
+	return paths
+}
+
+func (r *resolver) rlsState(subject string) string {
+	if !r.target.rlsEnabled {
+		return ""
+	}
+
+	s := r.roles[subject]
+	if s.superuser || s.bypassRLS {
+		return RLSBypassed
+	}
+
+	// Owners, and roles inheriting the owner's privileges, bypass RLS unless
+	// it is forced
+	if !r.target.rlsForced {
+		if subject == r.target.owner {
+			return RLSBypassed
+		}
+		if _, ok := r.graph.Paths(subject, true)[r.target.owner]; ok {
+			return RLSBypassed
+		}
+	}
+
+	return RLSEnforced
+}
@@ -3,104 +3,423 @@ package checker
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// InstanceInfo describes the server. RestartSettings lists the collected
+// settings that take effect only after a restart (pg_settings.context =
+// 'postmaster').
 type InstanceInfo struct {
-	Version  string            `json:"version"`
-	Settings map[string]string `json:"settings"`
+	Version         string            `json:"version"`
+	VersionNum      int               `json:"version_num"`
+	MajorVersion    int               `json:"major_version"`
+	MinorVersion    int               `json:"minor_version"`
+	Database        string            `json:"database"`
+	Settings        map[string]string `json:"settings"`
+	RestartSettings []string          `json:"restart_settings,omitempty"`
+	HBARules        []HBARule         `json:"hba_rules,omitempty"`
+	StatsReset      *time.Time        `json:"stats_reset,omitempty"`
 }
 
 type RoleInfo struct {
-	Name      string   `json:"name"`
-	Login     bool     `json:"login"`
-	Superuser bool     `json:"superuser"`
-	BypassRLS bool     `json:"bypassrls"`
-	Grants    []string `json:"grants"`
+	Name      string        `json:"name"`
+	Login     bool          `json:"login"`
+	Superuser bool          `json:"superuser"`
+	BypassRLS bool          `json:"bypassrls"`
+	Grants    []string      `json:"grants"`
+	Password  *PasswordInfo `json:"password,omitempty"`
+
+	ColumnGrants []ColumnGrant `json:"column_grants"`
+
+	MemberOf       []Membership `json:"member_of"`
+	EffectiveRoles []string     `json:"effective_roles"`
 }
 
 type TableInfo struct {
-	Schema     string `json:"schema"`
-	Name       string `json:"name"`
-	RLSEnabled bool   `json:"rls_enabled"`
+	Schema     string       `json:"schema"`
+	Name       string       `json:"name"`
+	RLSEnabled bool         `json:"rls_enabled"`
+	RLSForced  bool         `json:"rls_forced"`
+	Owner      string       `json:"owner"`
+	OwnerLogin bool         `json:"owner_login"`
+	Policies   []PolicyInfo `json:"policies"`
+	Accesses   int64        `json:"accesses"`
 }
 
 type Finding struct {
-	Severity string `json:"severity"`
-	Code     string `json:"code"`
-	Message  string `json:"message"`
+	Severity    string       `json:"severity"`
+	Code        string       `json:"code"`
+	Message     string       `json:"message"`
+	Database    string       `json:"database,omitempty"`
+	ObjectType  string       `json:"object_type,omitempty"`
+	Object      string       `json:"object,omitempty"`
+	Remediation *Remediation `json:"remediation,omitempty"`
 }
 
 type Report struct {
-	Instance InstanceInfo `json:"instance"`
-	Roles    []RoleInfo   `json:"roles"`
-	Tables   []TableInfo  `json:"tables"`
-	Findings []Finding    `json:"findings"`
+	Instance          InstanceInfo       `json:"instance"`
+	Roles             []RoleInfo         `json:"roles"`
+	Tables            []TableInfo        `json:"tables"`
+	PublicGrants      []PublicGrant      `json:"public_grants"`
+	Grants            []Grant            `json:"grants"`
+	DefaultPrivileges []DefaultPrivilege `json:"default_privileges"`
+	Functions         []FunctionInfo     `json:"functions"`
+	Extensions        []ExtensionInfo    `json:"extensions"`
+	Languages         []LanguageInfo     `json:"languages"`
+	PIIColumns        []PIIColumn        `json:"pii_columns"`
+	RoleActivity      []RoleActivity     `json:"role_activity"`
+	RoleCleanup       []RoleCleanup      `json:"role_cleanup"`
+	Sessions          []SessionInfo      `json:"sessions"`
+	Findings          []Finding          `json:"findings"`
+	Profile           *ProfileResult     `json:"profile,omitempty"`
+	Databases         []DatabaseReport   `json:"databases,omitempty"`
+	Timings           []CollectorTiming  `json:"timings"`
+	CoverageGaps      []CoverageGap      `json:"coverage_gaps"`
+}
+
+// Options tunes which findings Analyze reports
+type Options struct {
+	// SensitiveDatabases lists databases where CONNECT for PUBLIC is a finding
+	SensitiveDatabases []string
+
+	// MaskedColumns lists columns that policy.yaml exposes only through masks
+	MaskedColumns []MaskedColumn
+
+	// SettingChecks replaces the built-in settings catalog when set
+	SettingChecks []SettingCheck
+
+	// Profile, when set, is evaluated into Report.Profile
+	Profile *Profile
+
+	// Releases replaces the bundled release and CVE dataset when set
+	Releases *ReleaseData
+
+	// PIISampleRows enables sampling of up to this many rows per table when
+	// classifying PII columns; 0 classifies by name, type and comment only
+	PIISampleRows int
+
+	// RoleHistory maps roles to the last time they were seen connecting,
+	// from connection logs and earlier reports. Login roles are reported as
+	// never connecting only when it is set.
+	RoleHistory map[string]time.Time
+
+	// ClientAllowlist lists the networks clients may connect from; sessions
+	// from other addresses are reported when it is set
+	ClientAllowlist []*net.IPNet
+
+	// IdleInTransaction is how long a session may be idle in a transaction
+	// while holding locks (default 5 minutes)
+	IdleInTransaction time.Duration
+
+	// Concurrency bounds the collectors running at once when analyzing over
+	// a pool (default 4)
+	Concurrency int
 }
 
 func Analyze(ctx context.Context, conn *pgx.Conn) (*Report, error) {
-	report := &Report{
-		Roles:    []RoleInfo{},
-		Tables:   []TableInfo{},
-		Findings: []Finding{},
+	return AnalyzeWithOptions(ctx, conn, Options{})
+}
+
+// AnalyzeWithOptions analyzes over a single connection, one collector at a
+// time
+func AnalyzeWithOptions(ctx context.Context, conn *pgx.Conn, opts Options) (*Report, error) {
+	return analyze(ctx, conn, newCollectorRunner(1), opts)
+}
+
+// AnalyzePool analyzes over a pool, running up to opts.Concurrency
+// collectors at once
+func AnalyzePool(ctx context.Context, pool *pgxpool.Pool, opts Options) (*Report, error) {
+	return analyze(ctx, pool, newCollectorRunner(opts.Concurrency), opts)
+}
+
+func analyze(ctx context.Context, conn Querier, runner *collectorRunner, opts Options) (*Report, error) {
+	opts = opts.withDefaults()
+	report := newReport()
+
+	if err := collectCluster(ctx, conn, runner, opts, report); err != nil {
+		return nil, err
 	}
 
-	var err error
+	if err := collectDatabase(ctx, conn, runner, "", opts, report); err != nil {
+		return nil, err
+	}
 
-	report.Instance, err = getInstanceInfo(ctx, conn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get instance info: %w", err)
+	report.Findings = generateFindings(report, opts)
+	report.Timings = runner.timings
+	report.CoverageGaps = runner.coverageGaps()
+	finishReport(report, opts)
+
+	return report, nil
+}
+
+func newReport() *Report {
+	return &Report{
+		Roles:             []RoleInfo{},
+		Tables:            []TableInfo{},
+		PublicGrants:      []PublicGrant{},
+		Grants:            []Grant{},
+		DefaultPrivileges: []DefaultPrivilege{},
+		Functions:         []FunctionInfo{},
+		Extensions:        []ExtensionInfo{},
+		Languages:         []LanguageInfo{},
+		PIIColumns:        []PIIColumn{},
+		RoleActivity:      []RoleActivity{},
+		RoleCleanup:       []RoleCleanup{},
+		Sessions:          []SessionInfo{},
+		Findings:          []Finding{},
+		Timings:           []CollectorTiming{},
+		CoverageGaps:      []CoverageGap{},
 	}
+}
 
-	report.Roles, err = getRoles(ctx, conn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get roles: %w", err)
+func (opts Options) withDefaults() Options {
+	if opts.SettingChecks == nil {
+		opts.SettingChecks = DefaultSettingChecks()
+	}
+	if opts.Profile != nil {
+		opts.SettingChecks = withProfileSettings(opts.SettingChecks, opts.Profile)
 	}
+	if opts.Releases == nil {
+		opts.Releases = DefaultReleaseData()
+	}
+	return opts
+}
 
-	report.Tables, err = getTables(ctx, conn)
+// collectCluster reads the objects shared by all databases of the cluster:
+// server settings, roles and sessions
+func collectCluster(ctx context.Context, conn Querier, runner *collectorRunner, opts Options, report *Report) error {
+	var hbaRules []HBARule
+	var passwords map[string]*PasswordInfo
+
+	err := runner.run(ctx, conn, "", []collector{
+		{"instance", func(ctx context.Context, conn Querier) error {
+			names := settingNames(opts.SettingChecks)
+			info, err := getInstanceInfo(ctx, conn, names)
+			if err != nil {
+				return fmt.Errorf("failed to get instance info: %w", err)
+			}
+			report.Instance = info
+
+			// Superuser-only settings are left out of pg_settings without
+			// pg_read_all_settings
+			var hidden []string
+			for _, name := range names {
+				if _, ok := info.Settings[name]; !ok {
+					hidden = append(hidden, name)
+				}
+			}
+			if len(hidden) > 0 && !hasPrivilegesOf(ctx, conn, "pg_read_all_settings") {
+				runner.gap("instance", "", "settings not visible to this role: "+strings.Join(hidden, ", "))
+			}
+			return nil
+		}},
+		{"hba_rules", func(ctx context.Context, conn Querier) (err error) {
+			hbaRules, err = getHBARules(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get pg_hba rules: %w", err)
+			}
+			return nil
+		}},
+		{"roles", func(ctx context.Context, conn Querier) error {
+			roles, err := getRoles(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get roles: %w", err)
+			}
+			report.Roles = roles
+			return nil
+		}},
+		{"passwords", func(ctx context.Context, conn Querier) (err error) {
+			passwords, err = getPasswordInfo(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get password metadata: %w", err)
+			}
+			return nil
+		}},
+		{"role_activity", func(ctx context.Context, conn Querier) error {
+			activity, err := getRoleActivity(ctx, conn, opts.RoleHistory)
+			if err != nil {
+				return fmt.Errorf("failed to get role activity: %w", err)
+			}
+			report.RoleActivity = activity
+			return nil
+		}},
+		{"sessions", func(ctx context.Context, conn Querier) error {
+			sessions, err := getSessions(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get sessions: %w", err)
+			}
+			report.Sessions = sessions
+			if !hasPrivilegesOf(ctx, conn, "pg_read_all_stats") {
+				runner.gap("sessions", "", "client address, state and encryption of other roles' sessions are hidden")
+			}
+			return nil
+		}},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tables: %w", err)
+		return err
+	}
+
+	report.Instance.HBARules = hbaRules
+	for i := range report.Roles {
+		if info, ok := passwords[report.Roles[i].Name]; ok {
+			report.Roles[i].Password = info
+		}
 	}
 
-	report.Findings = generateFindings(report)
+	return nil
+}
 
-	return report, nil
+// collectDatabase reads the objects of the database conn is connected to.
+// report.Roles must already be filled; their grants in this database are
+// added.
+func collectDatabase(ctx context.Context, conn Querier, runner *collectorRunner, database string, opts Options, report *Report) error {
+	return runner.run(ctx, conn, database, []collector{
+		{"role_privileges", func(ctx context.Context, conn Querier) error {
+			if err := getRolePrivileges(ctx, conn, report.Roles); err != nil {
+				return fmt.Errorf("failed to get role privileges: %w", err)
+			}
+			return nil
+		}},
+		{"tables", func(ctx context.Context, conn Querier) error {
+			tables, err := getTables(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get tables: %w", err)
+			}
+			report.Tables = tables
+			return nil
+		}},
+		{"public_grants", func(ctx context.Context, conn Querier) error {
+			grants, err := getPublicGrants(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get PUBLIC grants: %w", err)
+			}
+			report.PublicGrants = grants
+			return nil
+		}},
+		{"grants", func(ctx context.Context, conn Querier) error {
+			grants, err := getGrants(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get grants: %w", err)
+			}
+			report.Grants = grants
+			return nil
+		}},
+		{"default_privileges", func(ctx context.Context, conn Querier) error {
+			defaults, err := getDefaultPrivileges(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get default privileges: %w", err)
+			}
+			report.DefaultPrivileges = defaults
+			return nil
+		}},
+		{"functions", func(ctx context.Context, conn Querier) error {
+			functions, err := getSecurityDefinerFunctions(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get functions: %w", err)
+			}
+			report.Functions = functions
+			return nil
+		}},
+		{"extensions", func(ctx context.Context, conn Querier) error {
+			extensions, err := getExtensions(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get extensions: %w", err)
+			}
+			report.Extensions = extensions
+			return nil
+		}},
+		{"languages", func(ctx context.Context, conn Querier) error {
+			languages, err := getLanguages(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get languages: %w", err)
+			}
+			report.Languages = languages
+			return nil
+		}},
+		{"pii_columns", func(ctx context.Context, conn Querier) error {
+			columns, skipped, err := getPIIColumns(ctx, conn, opts.PIISampleRows, opts.MaskedColumns)
+			if err != nil {
+				return fmt.Errorf("failed to classify PII columns: %w", err)
+			}
+			report.PIIColumns = columns
+			if len(skipped) > 0 {
+				runner.gap("pii_columns", database, "tables not sampled: "+strings.Join(skipped, ", "))
+			}
+			return nil
+		}},
+	})
 }
 
-func getInstanceInfo(ctx context.Context, conn *pgx.Conn) (InstanceInfo, error) {
-	var version string
-	err := conn.QueryRow(ctx, "SELECT version()").Scan(&version)
+// finishReport derives the sections built from the findings
+func finishReport(report *Report, opts Options) {
+	AttachRemediations(report.Findings)
+	for i := range report.Databases {
+		AttachRemediations(report.Databases[i].Findings)
+	}
+	report.RoleCleanup = roleCleanup(report.Findings)
+
+	if opts.Profile != nil {
+		report.Profile = EvaluateProfile(opts.Profile, report, opts.SettingChecks)
+	}
+}
+
+func getInstanceInfo(ctx context.Context, conn Querier, settingNames []string) (InstanceInfo, error) {
+	var version, database string
+	var versionNum int
+	err := conn.QueryRow(ctx,
+		"SELECT version(), current_setting('server_version_num')::int, current_database()").
+		Scan(&version, &versionNum, &database)
 	if err != nil {
 		return InstanceInfo{}, err
 	}
 
+	rows, err := conn.Query(ctx,
+		"SELECT name, setting, context = 'postmaster' FROM pg_settings WHERE name = ANY($1) ORDER BY name", settingNames)
+	if err != nil {
+		return InstanceInfo{}, err
+	}
+	defer rows.Close()
+
 	settings := make(map[string]string)
-	configParams := []string{
-		"ssl",
-		"password_encryption",
-		"log_connections",
-		"log_disconnections",
-		"log_statement",
-	}
-
-	for _, param := range configParams {
-		var value string
-		err := conn.QueryRow(ctx,
-			"SELECT setting FROM pg_settings WHERE name = $1", param).Scan(&value)
-		if err == nil {
-			settings[param] = value
+	var restart []string
+	for rows.Next() {
+		var name, value string
+		var postmaster bool
+		if err := rows.Scan(&name, &value, &postmaster); err != nil {
+			return InstanceInfo{}, err
+		}
+		settings[name] = value
+		if postmaster {
+			restart = append(restart, name)
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return InstanceInfo{}, err
+	}
+
+	info := InstanceInfo{
+		Version:         version,
+		VersionNum:      versionNum,
+		Database:        database,
+		Settings:        settings,
+		RestartSettings: restart,
+	}
+	info.MajorVersion, info.MinorVersion = parseVersionNum(versionNum)
 
-	return InstanceInfo{
-		Version:  version,
-		Settings: settings,
-	}, nil
+	info.StatsReset, err = getStatsReset(ctx, conn)
+	if err != nil {
+		return InstanceInfo{}, err
+	}
+
+	return info, nil
 }
 
-func getRoles(ctx context.Context, conn *pgx.Conn) ([]RoleInfo, error) {
+func getRoles(ctx context.Context, conn Querier) ([]RoleInfo, error) {
 	query := `
 		SELECT 
 			rolname,
@@ -124,6 +443,9 @@ func getRoles(ctx context.Context, conn *pgx.Conn) ([]RoleInfo, error) {
 		if err := rows.Scan(&role.Name, &role.Login, &role.Superuser, &role.BypassRLS); err != nil {
 			return nil, err
 		}
+		role.Grants = []string{}
+		role.MemberOf = []Membership{}
+		role.ColumnGrants = []ColumnGrant{}
 		roles = append(roles, role)
 	}
 
@@ -134,54 +456,69 @@ func getRoles(ctx context.Context, conn *pgx.Conn) ([]RoleInfo, error) {
 	// Close rows before making new queries
 	rows.Close()
 
-	// Now fetch grants for each role
-	for i := range roles {
-		grants, err := getRoleGrants(ctx, conn, roles[i].Name)
-		if err != nil {
-			return nil, err
-		}
-		roles[i].Grants = grants
+	if err := getMemberships(ctx, conn, roles); err != nil {
+		return nil, err
 	}
 
 	return roles, nil
 }
 
-func getRoleGrants(ctx context.Context, conn *pgx.Conn, roleName string) ([]string, error) {
+// getRolePrivileges fills the table and column grants of each role in the
+// current database with one query per kind
+func getRolePrivileges(ctx context.Context, conn Querier, roles []RoleInfo) error {
 	query := `
-		SELECT 
+		SELECT
+			grantee,
 			table_schema || '.' || table_name AS object,
 			privilege_type
 		FROM information_schema.role_table_grants
-		WHERE grantee = $1
-		ORDER BY table_schema, table_name, privilege_type
+		ORDER BY grantee, table_schema, table_name, privilege_type
 	`
 
-	rows, err := conn.Query(ctx, query, roleName)
+	rows, err := conn.Query(ctx, query)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var grants []string
+	grants := make(map[string][]string)
 	for rows.Next() {
-		var object, privilege string
-		if err := rows.Scan(&object, &privilege); err != nil {
-			return nil, err
+		var grantee, object, privilege string
+		if err := rows.Scan(&grantee, &object, &privilege); err != nil {
+			return err
 		}
-		grants = append(grants, fmt.Sprintf("%s ON %s", privilege, object))
+		grants[grantee] = append(grants[grantee], fmt.Sprintf("%s ON %s", privilege, object))
 	}
 
-	return grants, rows.Err()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows.Close()
+
+	for i := range roles {
+		roles[i].Grants = append([]string{}, grants[roles[i].Name]...)
+		roles[i].ColumnGrants = []ColumnGrant{}
+	}
+
+	return getColumnGrants(ctx, conn, roles)
 }
 
-func getTables(ctx context.Context, conn *pgx.Conn) ([]TableInfo, error) {
+func getTables(ctx context.Context, conn Querier) ([]TableInfo, error) {
 	query := `
 		SELECT 
 			n.nspname AS schema,
 			c.relname AS name,
-			c.relrowsecurity AS rls_enabled
+			c.relrowsecurity AS rls_enabled,
+			c.relforcerowsecurity AS rls_forced,
+			o.rolname AS owner,
+			o.rolcanlogin AS owner_login,
+			coalesce(s.seq_scan, 0) + coalesce(s.idx_scan, 0) +
+				coalesce(s.n_tup_ins, 0) + coalesce(s.n_tup_upd, 0) + coalesce(s.n_tup_del, 0) AS accesses
 		FROM pg_class c
 		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_roles o ON o.oid = c.relowner
+		LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
 		WHERE c.relkind = 'r'
 		  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
 		ORDER BY n.nspname, c.relname
@@ -196,53 +533,109 @@ func getTables(ctx context.Context, conn *pgx.Conn) ([]TableInfo, error) {
 	var tables []TableInfo
 	for rows.Next() {
 		var table TableInfo
-		if err := rows.Scan(&table.Schema, &table.Name, &table.RLSEnabled); err != nil {
+		if err := rows.Scan(&table.Schema, &table.Name, &table.RLSEnabled,
+			&table.RLSForced, &table.Owner, &table.OwnerLogin, &table.Accesses); err != nil {
 			return nil, err
 		}
+		table.Policies = []PolicyInfo{}
 		tables = append(tables, table)
 	}
 
-	return tables, rows.Err()
-}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-func generateFindings(report *Report) []Finding {
-	var findings []Finding
+	rows.Close()
 
-	for _, table := range report.Tables {
-		if !table.RLSEnabled {
-			findings = append(findings, Finding{
-				Severity: "warning",
-				Code:     "NO_RLS",
-				Message:  fmt.Sprintf("Table %s.%s has no RLS enabled", table.Schema, table.Name),
-			})
-		}
+	if err := getPolicies(ctx, conn, tables); err != nil {
+		return nil, err
 	}
 
+	return tables, nil
+}
+
+func generateFindings(report *Report, opts Options) []Finding {
+	findings := clusterFindings(report, opts)
+	findings = append(findings, databaseFindings(report, opts)...)
+	return findings
+}
+
+// clusterFindings checks the objects collected by collectCluster
+func clusterFindings(report *Report, opts Options) []Finding {
+	var findings []Finding
+
 	if ssl, ok := report.Instance.Settings["ssl"]; ok && strings.ToLower(ssl) == "off" {
 		findings = append(findings, Finding{
-			Severity: "high",
-			Code:     "SSL_DISABLED",
-			Message:  "SSL is disabled on this PostgreSQL instance",
+			Severity:    "high",
+			Code:        "SSL_DISABLED",
+			Message:     "SSL is disabled on this PostgreSQL instance",
+			ObjectType:  "setting",
+			Object:      "ssl",
+			Remediation: fix("ALTER SYSTEM SET ssl = on;"),
 		})
 	}
 
+	findings = append(findings, versionFindings(report.Instance, opts.Releases, time.Now())...)
+	findings = append(findings, settingFindings(report.Instance.Settings, opts.SettingChecks)...)
+	findings = append(findings, hbaFindings(report.Instance.HBARules)...)
+	findings = append(findings, passwordFindings(report)...)
+	findings = append(findings, membershipFindings(report.Roles)...)
+	findings = append(findings, dormantRoleFindings(report, opts.RoleHistory != nil)...)
+	findings = append(findings, sessionFindings(report.Sessions, opts.ClientAllowlist, opts.IdleInTransaction)...)
+
 	for _, role := range report.Roles {
 		if role.Superuser && role.Login {
 			findings = append(findings, Finding{
-				Severity: "critical",
-				Code:     "SUPERUSER_LOGIN",
-				Message:  fmt.Sprintf("Role %s is a superuser with login capability", role.Name),
+				Severity:    "critical",
+				Code:        "SUPERUSER_LOGIN",
+				Message:     fmt.Sprintf("Role %s is a superuser with login capability", role.Name),
+				ObjectType:  "role",
+				Object:      role.Name,
+				Remediation: fix(fmt.Sprintf("ALTER ROLE %s NOSUPERUSER;", quoteIdentAlways(role.Name))),
 			})
 		}
 
 		if role.BypassRLS {
 			findings = append(findings, Finding{
-				Severity: "warning",
-				Code:     "BYPASS_RLS",
-				Message:  fmt.Sprintf("Role %s can bypass RLS policies", role.Name),
+				Severity:    "warning",
+				Code:        "BYPASS_RLS",
+				Message:     fmt.Sprintf("Role %s can bypass RLS policies", role.Name),
+				ObjectType:  "role",
+				Object:      role.Name,
+				Remediation: fix(fmt.Sprintf("ALTER ROLE %s NOBYPASSRLS;", quoteIdentAlways(role.Name))),
 			})
 		}
 	}
 
 	return findings
 }
+
+// databaseFindings checks the objects collected by collectDatabase
+func databaseFindings(report *Report, opts Options) []Finding {
+	var findings []Finding
+
+	for _, table := range report.Tables {
+		if !table.RLSEnabled {
+			findings = append(findings, Finding{
+				Severity:    "warning",
+				Code:        "NO_RLS",
+				Message:     fmt.Sprintf("Table %s.%s has no RLS enabled", table.Schema, table.Name),
+				ObjectType:  "table",
+				Object:      table.Schema + "." + table.Name,
+				Remediation: fix(fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY;", quoteQualified(table.Schema, table.Name))),
+			})
+		}
+	}
+
+	findings = append(findings, rlsFindings(report.Tables)...)
+	findings = append(findings, maskedColumnFindings(report.Roles, opts.MaskedColumns)...)
+	findings = append(findings, piiFindings(report.PIIColumns, report.Roles, report.PublicGrants)...)
+	findings = append(findings, unusedGrantFindings(report)...)
+	findings = append(findings, publicGrantFindings(report.PublicGrants, opts)...)
+	findings = append(findings, grantFindings(report.Grants)...)
+	findings = append(findings, defaultPrivilegeFindings(report.DefaultPrivileges)...)
+	findings = append(findings, functionFindings(report.Functions)...)
+	findings = append(findings, extensionFindings(report.Extensions, report.Languages)...)
+
+	return findings
+}
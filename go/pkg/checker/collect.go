@@ -0,0 +1,180 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// defaultConcurrency is how many collectors run at once over a pool
+const defaultConcurrency = 4
+
+// Querier is implemented by *pgx.Conn and *pgxpool.Pool. A *pgx.Conn must not
+// be used by more than one collector at a time.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// CollectorTiming is how long one collector took; Database is set for
+// database-scoped collectors of a multi-database scan
+type CollectorTiming struct {
+	Collector  string `json:"collector"`
+	Database   string `json:"database,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// CoverageGap is a part of the report that could not be collected with the
+// privileges of the connecting role. Grant names what would close it.
+type CoverageGap struct {
+	Collector string `json:"collector"`
+	Database  string `json:"database,omitempty"`
+	Reason    string `json:"reason"`
+	Grant     string `json:"grant,omitempty"`
+}
+
+// collectorGrants are the minimal privileges each collector needs beyond
+// what every role can read
+var collectorGrants = map[string]string{
+	"instance":    "pg_read_all_settings",
+	"hba_rules":   "GRANT SELECT ON pg_hba_file_rules and EXECUTE ON FUNCTION pg_hba_file_rules() (or superuser)",
+	"passwords":   "superuser (pg_authid is not readable otherwise)",
+	"sessions":    "pg_read_all_stats",
+	"pii_columns": "pg_read_all_data, or SELECT on the sampled tables",
+	"database":    "CONNECT on the database",
+}
+
+// permissionDenied returns the server message of an insufficient_privilege
+// error
+func permissionDenied(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "42501" {
+		return pgErr.Message, true
+	}
+	return "", false
+}
+
+// hasPrivilegesOf reports whether the connecting role has the privileges of
+// a predefined role such as pg_read_all_stats; superusers always do
+func hasPrivilegesOf(ctx context.Context, conn Querier, role string) bool {
+	var ok bool
+	err := conn.QueryRow(ctx, "SELECT pg_has_role(current_user, $1, 'USAGE')", role).Scan(&ok)
+	return err == nil && ok
+}
+
+// collector reads one part of the report. Collectors of the same stage run
+// concurrently, so each must write only its own report fields.
+type collector struct {
+	name string
+	run  func(ctx context.Context, conn Querier) error
+}
+
+// collectorRunner bounds the collectors running at once across all
+// databases and records their timings
+type collectorRunner struct {
+	slots chan struct{}
+
+	mu      sync.Mutex
+	timings []CollectorTiming
+	gaps    []CoverageGap
+}
+
+func newCollectorRunner(concurrency int) *collectorRunner {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	return &collectorRunner{slots: make(chan struct{}, concurrency)}
+}
+
+// run executes the collectors over conn and waits for all of them. A
+// collector denied access is recorded as a coverage gap; any other error
+// cancels the collectors still running and is returned.
+func (r *collectorRunner) run(ctx context.Context, conn Querier, database string, collectors []collector) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	timings := make([]CollectorTiming, len(collectors))
+
+	for i, c := range collectors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case r.slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-r.slots }()
+
+			start := time.Now()
+			err := c.run(ctx, conn)
+			timings[i] = CollectorTiming{
+				Collector:  c.name,
+				Database:   database,
+				DurationMS: time.Since(start).Milliseconds(),
+			}
+
+			if reason, ok := permissionDenied(err); ok {
+				r.gap(c.name, database, reason)
+				return
+			}
+			if err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				errMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.timings = append(r.timings, timings...)
+	r.mu.Unlock()
+
+	return nil
+}
+
+// gap records a part of the report that could not be collected
+func (r *collectorRunner) gap(collector, database, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gaps = append(r.gaps, CoverageGap{
+		Collector: collector,
+		Database:  database,
+		Reason:    reason,
+		Grant:     collectorGrants[collector],
+	})
+}
+
+// coverageGaps returns the recorded gaps ordered by database and collector
+func (r *collectorRunner) coverageGaps() []CoverageGap {
+	gaps := append([]CoverageGap{}, r.gaps...)
+	sort.SliceStable(gaps, func(i, j int) bool {
+		if gaps[i].Database != gaps[j].Database {
+			return gaps[i].Database < gaps[j].Database
+		}
+		return gaps[i].Collector < gaps[j].Collector
+	})
+	return gaps
+}
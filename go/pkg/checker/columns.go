@@ -0,0 +1,125 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ColumnGrant is a privilege granted on individual columns rather than the
+// whole table, e.g. GRANT SELECT (email) ON customers
+type ColumnGrant struct {
+	Object    string `json:"object"`
+	Column    string `json:"column"`
+	Privilege string `json:"privilege"`
+}
+
+func (g ColumnGrant) String() string {
+	return fmt.Sprintf("%s (%s) ON %s", g.Privilege, g.Column, g.Object)
+}
+
+// MaskedColumn is a column that must only be exposed through a masking view
+type MaskedColumn struct {
+	Table  string
+	Column string
+}
+
+// getColumnGrants reads pg_attribute.attacl for all roles at once and
+// attaches the column privileges to the matching roles
+func getColumnGrants(ctx context.Context, conn Querier, roles []RoleInfo) error {
+	query := `
+		SELECT
+			pg_get_userbyid(a.grantee),
+			n.nspname || '.' || c.relname,
+			att.attname,
+			a.privilege_type
+		FROM pg_attribute att
+		JOIN pg_class c ON c.oid = att.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace,
+			aclexplode(att.attacl) a
+		WHERE att.attacl IS NOT NULL
+		  AND att.attnum > 0
+		  AND NOT att.attisdropped
+		  AND a.grantee <> 0
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+		ORDER BY 2, 3, 4
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[string]int)
+	for i, r := range roles {
+		index[r.Name] = i
+	}
+
+	for rows.Next() {
+		var grantee string
+		var g ColumnGrant
+		if err := rows.Scan(&grantee, &g.Object, &g.Column, &g.Privilege); err != nil {
+			return err
+		}
+		if i, ok := index[grantee]; ok {
+			roles[i].ColumnGrants = append(roles[i].ColumnGrants, g)
+		}
+	}
+
+	return rows.Err()
+}
+
+func maskedColumnFindings(roles []RoleInfo, masked []MaskedColumn) []Finding {
+	var findings []Finding
+
+	for _, role := range roles {
+		if role.Superuser {
+			continue
+		}
+
+		tableGrants := make(map[string]bool)
+		for _, g := range role.Grants {
+			tableGrants[g] = true
+		}
+
+		for _, m := range masked {
+			schema, table := splitQualified(m.Table)
+			qualified := quoteQualified(schema, table)
+
+			if tableGrants["SELECT ON "+schema+"."+table] {
+				findings = append(findings, Finding{
+					Severity:    "high",
+					Code:        "MASKED_COLUMN_EXPOSED",
+					Message:     fmt.Sprintf("Role %s can read masked column %s of %s.%s directly through a table-level SELECT grant", role.Name, m.Column, schema, table),
+					ObjectType:  "role",
+					Object:      role.Name,
+					Remediation: fix(fmt.Sprintf("REVOKE SELECT ON %s FROM %s;", qualified, quoteIdentAlways(role.Name))),
+				})
+			}
+
+			for _, cg := range role.ColumnGrants {
+				if cg.Object == schema+"."+table && cg.Column == m.Column && cg.Privilege == "SELECT" {
+					findings = append(findings, Finding{
+						Severity:    "high",
+						Code:        "MASKED_COLUMN_EXPOSED",
+						Message:     fmt.Sprintf("Role %s has a direct column grant on masked column %s of %s.%s", role.Name, m.Column, schema, table),
+						ObjectType:  "role",
+						Object:      role.Name,
+						Remediation: fix(fmt.Sprintf("REVOKE SELECT (%s) ON %s FROM %s;", quoteIdentAlways(m.Column), qualified, quoteIdentAlways(role.Name))),
+					})
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+func splitQualified(name string) (schema, table string) {
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return "public", parts[0]
+}
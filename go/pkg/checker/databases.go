@@ -0,0 +1,265 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DatabaseReport is the database-scoped part of a multi-database scan. Roles
+// carry their table and column grants in this database.
+type DatabaseReport struct {
+	Name              string             `json:"name"`
+	StatsReset        *time.Time         `json:"stats_reset,omitempty"`
+	Roles             []RoleInfo         `json:"roles"`
+	Tables            []TableInfo        `json:"tables"`
+	PublicGrants      []PublicGrant      `json:"public_grants"`
+	Grants            []Grant            `json:"grants"`
+	DefaultPrivileges []DefaultPrivilege `json:"default_privileges"`
+	Functions         []FunctionInfo     `json:"functions"`
+	Extensions        []ExtensionInfo    `json:"extensions"`
+	Languages         []LanguageInfo     `json:"languages"`
+	PIIColumns        []PIIColumn        `json:"pii_columns"`
+	Findings          []Finding          `json:"findings"`
+}
+
+// ConnectFunc opens a pool of at most maxConns connections to another
+// database of the same cluster
+type ConnectFunc func(ctx context.Context, database string, maxConns int) (*pgxpool.Pool, error)
+
+// ListDatabases returns the databases that accept connections, filtered by
+// shell-style include and exclude patterns (no include means all)
+func ListDatabases(ctx context.Context, conn Querier, include, exclude []string) ([]string, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT datname
+		FROM pg_database
+		WHERE datallowconn AND NOT datistemplate
+		ORDER BY datname
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		if len(include) > 0 && !matchesAny(include, name) {
+			continue
+		}
+		if matchesAny(exclude, name) {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalyzeDatabases runs the cluster-wide checks once over pool and the
+// database-scoped checks in every listed database. Up to opts.Concurrency
+// databases are scanned at once, each over its own pool that is closed before
+// the next database is opened; together these pools hold at most
+// opts.Concurrency connections, and the connections of pool are closed before
+// they open. opts.Concurrency also bounds the collectors
+// running at once. Database findings are tagged with their database and also
+// listed in Report.Findings.
+func AnalyzeDatabases(ctx context.Context, pool *pgxpool.Pool, connect ConnectFunc, databases []string, opts Options) (*Report, error) {
+	opts = opts.withDefaults()
+	report := newReport()
+	runner := newCollectorRunner(opts.Concurrency)
+
+	if err := collectCluster(ctx, pool, runner, opts, report); err != nil {
+		return nil, err
+	}
+	// pool is not used again; its idle connections would count on top of the
+	// per-database pools
+	pool.Reset()
+
+	results := make([]*DatabaseReport, len(databases))
+	shared := make([]*Report, len(databases))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+
+	workers := min(cap(runner.slots), len(databases))
+	maxConns := max(1, cap(runner.slots)/max(workers, 1))
+	next := make(chan int)
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				name := databases[i]
+				db, sh, err := analyzeDatabase(ctx, connect, maxConns, runner, name, report, opts)
+				if reason, ok := permissionDenied(err); ok {
+					runner.gap("database", name, reason)
+					continue
+				}
+				if err != nil {
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("failed to analyze database %s: %w", name, err)
+						cancel()
+					}
+					errMu.Unlock()
+					continue
+				}
+				results[i], shared[i] = db, sh
+			}
+		}()
+	}
+
+feed:
+	for i := range databases {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	findings := clusterFindings(report, opts)
+	sharedTaken := false
+	for i, db := range results {
+		if db == nil {
+			continue
+		}
+
+		// Databases and tablespaces live in shared catalogs, so every
+		// database reports the same ACLs for them
+		if !sharedTaken {
+			report.PublicGrants = shared[i].PublicGrants
+			report.Grants = shared[i].Grants
+			sharedTaken = true
+		}
+
+		report.Databases = append(report.Databases, *db)
+		findings = append(findings, db.Findings...)
+	}
+
+	findings = append(findings, publicGrantFindings(report.PublicGrants, opts)...)
+	findings = append(findings, grantFindings(report.Grants)...)
+
+	report.Findings = findings
+	report.Timings = runner.timings
+	report.CoverageGaps = runner.coverageGaps()
+	finishReport(report, opts)
+
+	return report, nil
+}
+
+// analyzeDatabase collects and checks one database. The grants on shared
+// objects are returned separately in shared and left out of the result.
+func analyzeDatabase(ctx context.Context, connect ConnectFunc, maxConns int, runner *collectorRunner, name string, cluster *Report, opts Options) (*DatabaseReport, *Report, error) {
+	pool, err := connect(ctx, name, maxConns)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer pool.Close()
+
+	scoped := newReport()
+	scoped.Instance = cluster.Instance
+	scoped.Instance.Database = name
+
+	scoped.Roles = make([]RoleInfo, len(cluster.Roles))
+	copy(scoped.Roles, cluster.Roles)
+
+	err = runner.run(ctx, pool, name, []collector{
+		{"stats_reset", func(ctx context.Context, conn Querier) error {
+			statsReset, err := getStatsReset(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("failed to get statistics reset time: %w", err)
+			}
+			scoped.Instance.StatsReset = statsReset
+			return nil
+		}},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := collectDatabase(ctx, pool, runner, name, opts, scoped); err != nil {
+		return nil, nil, err
+	}
+
+	shared := newReport()
+	scoped.PublicGrants, shared.PublicGrants = splitSharedPublicGrants(scoped.PublicGrants)
+	scoped.Grants, shared.Grants = splitSharedGrants(scoped.Grants)
+
+	findings := databaseFindings(scoped, opts)
+	for i := range findings {
+		findings[i].Database = name
+	}
+
+	return &DatabaseReport{
+		Name:              name,
+		StatsReset:        scoped.Instance.StatsReset,
+		Roles:             scoped.Roles,
+		Tables:            scoped.Tables,
+		PublicGrants:      scoped.PublicGrants,
+		Grants:            scoped.Grants,
+		DefaultPrivileges: scoped.DefaultPrivileges,
+		Functions:         scoped.Functions,
+		Extensions:        scoped.Extensions,
+		Languages:         scoped.Languages,
+		PIIColumns:        scoped.PIIColumns,
+		Findings:          append([]Finding{}, findings...),
+	}, shared, nil
+}
+
+func splitSharedPublicGrants(grants []PublicGrant) (local, shared []PublicGrant) {
+	local, shared = []PublicGrant{}, []PublicGrant{}
+	for _, g := range grants {
+		if g.ObjectType == "database" {
+			shared = append(shared, g)
+		} else {
+			local = append(local, g)
+		}
+	}
+	return local, shared
+}
+
+func splitSharedGrants(grants []Grant) (local, shared []Grant) {
+	local, shared = []Grant{}, []Grant{}
+	for _, g := range grants {
+		if g.ObjectKind == "database" || g.ObjectKind == "tablespace" {
+			shared = append(shared, g)
+		} else {
+			local = append(local, g)
+		}
+	}
+	return local, shared
+}
@@ -0,0 +1,116 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultPrivilege is one entry of ALTER DEFAULT PRIVILEGES. An empty Schema
+// means the default applies in every schema. Global entries start from
+// acldefault, so the built-in grants they carry (PUBLIC EXECUTE on functions,
+// USAGE on types) are left out: every server has them without any entry.
+type DefaultPrivilege struct {
+	Role         string `json:"role"`
+	Schema       string `json:"schema,omitempty"`
+	ObjectType   string `json:"object_type"`
+	Grantee      string `json:"grantee"`
+	GranteeLogin bool   `json:"grantee_login"`
+	Privilege    string `json:"privilege"`
+	Grantable    bool   `json:"grantable"`
+}
+
+func getDefaultPrivileges(ctx context.Context, conn Querier) ([]DefaultPrivilege, error) {
+	query := `
+		SELECT
+			pg_get_userbyid(d.defaclrole),
+			coalesce(quote_ident(n.nspname), ''),
+			CASE d.defaclobjtype
+				WHEN 'r' THEN 'tables'
+				WHEN 'S' THEN 'sequences'
+				WHEN 'f' THEN 'functions'
+				WHEN 'T' THEN 'types'
+				WHEN 'n' THEN 'schemas'
+				ELSE d.defaclobjtype::text
+			END,
+			CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee) END,
+			coalesce(r.rolcanlogin, false),
+			a.privilege_type,
+			a.is_grantable
+		FROM pg_default_acl d
+		LEFT JOIN pg_namespace n ON n.oid = d.defaclnamespace,
+			aclexplode(d.defaclacl) a
+		LEFT JOIN pg_roles r ON r.oid = a.grantee
+		WHERE a.grantee <> d.defaclrole
+		  AND NOT (d.defaclnamespace = 0 AND EXISTS (
+			SELECT 1 FROM aclexplode(acldefault(d.defaclobjtype, d.defaclrole)) b
+			WHERE b.grantee = a.grantee
+			  AND b.privilege_type = a.privilege_type
+			  AND b.is_grantable = a.is_grantable))
+		ORDER BY 1, 2, 3, 4, 6
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var defaults []DefaultPrivilege
+	for rows.Next() {
+		var d DefaultPrivilege
+		if err := rows.Scan(&d.Role, &d.Schema, &d.ObjectType, &d.Grantee,
+			&d.GranteeLogin, &d.Privilege, &d.Grantable); err != nil {
+			return nil, err
+		}
+		defaults = append(defaults, d)
+	}
+
+	return defaults, rows.Err()
+}
+
+func defaultPrivilegeFindings(defaults []DefaultPrivilege) []Finding {
+	var findings []Finding
+
+	for _, d := range defaults {
+		scope := "in all schemas"
+		inSchema := ""
+		if d.Schema != "" {
+			scope = "in schema " + d.Schema
+			inSchema = " IN SCHEMA " + d.Schema
+		}
+
+		grantee := d.Grantee
+		if grantee != "PUBLIC" {
+			grantee = quoteIdentAlways(grantee)
+		}
+		remediation := fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s%s REVOKE %s ON %s FROM %s;",
+			quoteIdentAlways(d.Role), inSchema, d.Privilege, d.ObjectType, grantee)
+
+		var f Finding
+		switch {
+		case d.Grantee == "PUBLIC":
+			f = Finding{
+				Severity: "high",
+				Code:     "DEFAULT_ACL_PUBLIC",
+				Message: fmt.Sprintf("New %s created by %s %s get %s for PUBLIC by default",
+					d.ObjectType, d.Role, scope, d.Privilege),
+			}
+		case d.GranteeLogin:
+			f = Finding{
+				Severity: "warning",
+				Code:     "DEFAULT_ACL_LOGIN_ROLE",
+				Message: fmt.Sprintf("New %s created by %s %s get %s for login role %s by default",
+					d.ObjectType, d.Role, scope, d.Privilege, d.Grantee),
+			}
+		default:
+			continue
+		}
+
+		f.ObjectType = "default_acl"
+		f.Object = d.Role
+		f.Remediation = fix(remediation)
+		findings = append(findings, f)
+	}
+
+	return findings
+}
@@ -0,0 +1,332 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RoleActivity records whether a role is in use. LastSeen comes from the
+// current pg_stat_activity snapshot, connection logs and earlier reports.
+type RoleActivity struct {
+	Role         string     `json:"role"`
+	Sessions     int        `json:"sessions"`
+	LastSeen     *time.Time `json:"last_seen,omitempty"`
+	OwnedObjects int        `json:"owned_objects"`
+	ACLEntries   int        `json:"acl_entries"`
+}
+
+// RoleCleanup is a recommended cleanup step for a dormant or unused role
+type RoleCleanup struct {
+	Role     string   `json:"role"`
+	Database string   `json:"database,omitempty"`
+	Reasons  []string `json:"reasons"`
+	SQL      []string `json:"sql"`
+}
+
+// cleanupCodes are the finding codes collected into Report.RoleCleanup
+var cleanupCodes = []string{"ROLE_NEVER_CONNECTS", "ROLE_UNUSED", "ROLE_GRANTS_UNUSED"}
+
+func getRoleActivity(ctx context.Context, conn Querier, history map[string]time.Time) ([]RoleActivity, error) {
+	var names []string
+	sessions := make(map[string]int)
+	rows, err := conn.Query(ctx, `
+		SELECT r.rolname, count(a.pid)
+		FROM pg_roles r
+		LEFT JOIN pg_stat_activity a ON a.usesysid = r.oid
+		WHERE r.rolname NOT LIKE 'pg_%'
+		GROUP BY r.rolname
+		ORDER BY r.rolname
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		var n int
+		if err := rows.Scan(&name, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+		sessions[name] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// pg_shdepend is shared by all databases: it records owners ('o') and
+	// roles mentioned in ACLs ('a') or policies ('r') of every object
+	owned := make(map[string]int)
+	referenced := make(map[string]int)
+	rows, err = conn.Query(ctx, `
+		SELECT
+			r.rolname,
+			count(*) FILTER (WHERE d.deptype = 'o'),
+			count(*) FILTER (WHERE d.deptype IN ('a', 'r'))
+		FROM pg_shdepend d
+		JOIN pg_roles r ON r.oid = d.refobjid
+		WHERE d.refclassid = 'pg_authid'::regclass
+		GROUP BY r.rolname
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		var o, a int
+		if err := rows.Scan(&name, &o, &a); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		owned[name] = o
+		referenced[name] = a
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	activity := make([]RoleActivity, 0, len(names))
+	for _, name := range names {
+		a := RoleActivity{
+			Role:         name,
+			Sessions:     sessions[name],
+			OwnedObjects: owned[name],
+			ACLEntries:   referenced[name],
+		}
+		if seen, ok := history[name]; ok {
+			a.LastSeen = &seen
+		}
+		if a.Sessions > 0 {
+			a.LastSeen = &now
+		}
+		activity = append(activity, a)
+	}
+
+	return activity, nil
+}
+
+func getStatsReset(ctx context.Context, conn Querier) (*time.Time, error) {
+	var reset *time.Time
+	err := conn.QueryRow(ctx,
+		"SELECT stats_reset FROM pg_stat_database WHERE datname = current_database()").Scan(&reset)
+	return reset, err
+}
+
+var connectionLogRe = regexp.MustCompile(`connection (?:authorized|authenticated):.*?\buser="?([^\s",]+)`)
+
+// ParseConnectionLog adds the roles seen in "connection authorized" lines of
+// a PostgreSQL log (stderr or csvlog, log_connections = on) to seen. Lines
+// whose timestamp cannot be parsed count as seen at the file's mtime.
+func ParseConnectionLog(path string, seen map[string]time.Time) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open connection log: %w", err)
+	}
+	defer f.Close()
+
+	fallback := time.Now().UTC()
+	if info, err := f.Stat(); err == nil {
+		fallback = info.ModTime().UTC()
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		m := connectionLogRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		ts := fallback
+		if len(line) >= 19 {
+			if t, err := time.Parse("2006-01-02 15:04:05", line[:19]); err == nil {
+				ts = t
+			}
+		}
+		markSeen(seen, m[1], ts)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read connection log: %w", err)
+	}
+
+	return nil
+}
+
+// ActivityFromReport adds the roles seen according to an earlier report to
+// seen, so that snapshots from repeated runs accumulate
+func ActivityFromReport(r *Report, seen map[string]time.Time) {
+	for _, a := range r.RoleActivity {
+		if a.LastSeen != nil {
+			markSeen(seen, a.Role, *a.LastSeen)
+		}
+	}
+}
+
+func markSeen(seen map[string]time.Time, role string, t time.Time) {
+	if prev, ok := seen[role]; !ok || t.After(prev) {
+		seen[role] = t
+	}
+}
+
+// dormantRoleFindings reports unused roles across the cluster. Login roles
+// are only reported as never connecting when history (logs or earlier
+// reports) was provided, since a single pg_stat_activity snapshot proves
+// nothing.
+func dormantRoleFindings(report *Report, haveHistory bool) []Finding {
+	var findings []Finding
+
+	activity := make(map[string]RoleActivity)
+	for _, a := range report.RoleActivity {
+		activity[a.Role] = a
+	}
+
+	members := make(map[string]bool)
+	for _, r := range report.Roles {
+		for _, m := range r.MemberOf {
+			members[m.Role] = true
+		}
+	}
+
+	aclGrantees := make(map[string]bool)
+	for _, g := range report.Grants {
+		aclGrantees[g.Grantee] = true
+	}
+	for _, d := range report.DefaultPrivileges {
+		aclGrantees[d.Grantee] = true
+		aclGrantees[d.Role] = true
+	}
+
+	for _, role := range report.Roles {
+		if role.Superuser {
+			continue
+		}
+		a := activity[role.Name]
+		ident := quoteIdentAlways(role.Name)
+		neverSeen := haveHistory && a.LastSeen == nil
+
+		if role.Login && neverSeen {
+			findings = append(findings, Finding{
+				Severity:    "warning",
+				Code:        "ROLE_NEVER_CONNECTS",
+				Message:     fmt.Sprintf("Login role %s has no recorded connections in pg_stat_activity, connection logs or earlier reports", role.Name),
+				ObjectType:  "role",
+				Object:      role.Name,
+				Remediation: fix(fmt.Sprintf("ALTER ROLE %s NOLOGIN;", ident)),
+			})
+		}
+
+		hasGrants := len(role.Grants) > 0 || len(role.ColumnGrants) > 0 ||
+			a.ACLEntries > 0 || aclGrantees[role.Name]
+		if !hasGrants && !members[role.Name] && len(role.MemberOf) == 0 &&
+			a.OwnedObjects == 0 && (!role.Login || neverSeen) {
+			findings = append(findings, Finding{
+				Severity:    "low",
+				Code:        "ROLE_UNUSED",
+				Message:     fmt.Sprintf("Role %s has no grants, no members, no memberships and owns no objects", role.Name),
+				ObjectType:  "role",
+				Object:      role.Name,
+				Remediation: fix(fmt.Sprintf("DROP ROLE %s;", ident)),
+			})
+		}
+	}
+
+	return findings
+}
+
+// unusedGrantFindings reports table grants in the current database on tables
+// nobody has read or written. pg_stat_user_tables counts accesses per table,
+// not per role, so a grant on a table another role uses is not reported.
+func unusedGrantFindings(report *Report) []Finding {
+	var findings []Finding
+
+	tableAccesses := make(map[string]int64)
+	for _, t := range report.Tables {
+		tableAccesses[t.Schema+"."+t.Name] = t.Accesses
+	}
+
+	since := "since statistics were last reset"
+	if reset := report.Instance.StatsReset; reset != nil {
+		since = "since " + reset.Format("2006-01-02")
+	}
+
+	for _, role := range report.Roles {
+		if role.Superuser {
+			continue
+		}
+		ident := quoteIdentAlways(role.Name)
+
+		var unused []string
+		var revokes []string
+		for _, g := range role.Grants {
+			privilege, object, ok := strings.Cut(g, " ON ")
+			if !ok {
+				continue
+			}
+			if accesses, known := tableAccesses[object]; !known || accesses > 0 {
+				continue
+			}
+			unused = append(unused, g)
+			schema, table := splitQualified(object)
+			revokes = append(revokes, fmt.Sprintf("REVOKE %s ON %s FROM %s;", privilege, quoteQualified(schema, table), ident))
+		}
+		if len(unused) > 0 {
+			findings = append(findings, Finding{
+				Severity:    "low",
+				Code:        "ROLE_GRANTS_UNUSED",
+				Message:     fmt.Sprintf("Role %s holds %d grant(s) on tables that nobody has read or written %s: %s", role.Name, len(unused), since, strings.Join(unused, ", ")),
+				ObjectType:  "role",
+				Object:      role.Name,
+				Remediation: fix(strings.Join(revokes, "\n")),
+			})
+		}
+	}
+
+	return findings
+}
+
+// roleCleanup groups the dormant role findings into one list per role and
+// database the SQL has to run in
+func roleCleanup(findings []Finding) []RoleCleanup {
+	byRole := make(map[string]*RoleCleanup)
+	for _, f := range findings {
+		if !contains(cleanupCodes, f.Code) {
+			continue
+		}
+		key := f.Object + "\x00" + f.Database
+		c, ok := byRole[key]
+		if !ok {
+			c = &RoleCleanup{Role: f.Object, Database: f.Database}
+			byRole[key] = c
+		}
+		c.Reasons = append(c.Reasons, f.Message)
+		if f.Remediation != nil && f.Remediation.SQL != "" {
+			c.SQL = append(c.SQL, strings.Split(f.Remediation.SQL, "\n")...)
+		}
+	}
+
+	cleanup := []RoleCleanup{}
+	for _, name := range sortedRoleNames(byRole) {
+		cleanup = append(cleanup, *byRole[name])
+	}
+	return cleanup
+}
+
+func sortedRoleNames(m map[string]*RoleCleanup) []string {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
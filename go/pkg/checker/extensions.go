@@ -0,0 +1,183 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ExtensionInfo struct {
+	Name              string   `json:"name"`
+	Version           string   `json:"version"`
+	Schema            string   `json:"schema"`
+	DefaultVersion    string   `json:"default_version,omitempty"`
+	AvailableVersions []string `json:"available_versions"`
+}
+
+type LanguageInfo struct {
+	Name      string `json:"name"`
+	Trusted   bool   `json:"trusted"`
+	Owner     string `json:"owner"`
+	Functions int    `json:"functions"`
+}
+
+// dangerousExtensions give database users access to other servers or to the
+// server's file system
+var dangerousExtensions = map[string]string{
+	"dblink":    "opens connections to other servers from inside the database",
+	"adminpack": "reads and writes files on the database server",
+	"file_fdw":  "reads files and program output on the database server",
+}
+
+func getExtensions(ctx context.Context, conn Querier) ([]ExtensionInfo, error) {
+	query := `
+		SELECT
+			e.extname,
+			e.extversion,
+			n.nspname,
+			coalesce(a.default_version, ''),
+			ARRAY(
+				SELECT v.version FROM pg_available_extension_versions v
+				WHERE v.name = e.extname
+				ORDER BY v.version
+			)
+		FROM pg_extension e
+		JOIN pg_namespace n ON n.oid = e.extnamespace
+		LEFT JOIN pg_available_extensions a ON a.name = e.extname
+		ORDER BY e.extname
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var extensions []ExtensionInfo
+	for rows.Next() {
+		var e ExtensionInfo
+		if err := rows.Scan(&e.Name, &e.Version, &e.Schema, &e.DefaultVersion, &e.AvailableVersions); err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, e)
+	}
+
+	return extensions, rows.Err()
+}
+
+func getLanguages(ctx context.Context, conn Querier) ([]LanguageInfo, error) {
+	query := `
+		SELECT
+			l.lanname,
+			l.lanpltrusted,
+			pg_get_userbyid(l.lanowner),
+			(SELECT count(*) FROM pg_proc p WHERE p.prolang = l.oid)
+		FROM pg_language l
+		WHERE l.lanname NOT IN ('internal', 'c', 'sql')
+		ORDER BY l.lanname
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var languages []LanguageInfo
+	for rows.Next() {
+		var l LanguageInfo
+		if err := rows.Scan(&l.Name, &l.Trusted, &l.Owner, &l.Functions); err != nil {
+			return nil, err
+		}
+		languages = append(languages, l)
+	}
+
+	return languages, rows.Err()
+}
+
+func extensionFindings(extensions []ExtensionInfo, languages []LanguageInfo) []Finding {
+	var findings []Finding
+
+	for _, e := range extensions {
+		add := func(severity, code, message, remediation string) {
+			findings = append(findings, Finding{
+				Severity:    severity,
+				Code:        code,
+				Message:     message,
+				ObjectType:  "extension",
+				Object:      e.Name,
+				Remediation: fix(remediation),
+			})
+		}
+
+		if reason, ok := dangerousExtensions[e.Name]; ok {
+			add("high", "EXTENSION_DANGEROUS",
+				fmt.Sprintf("Extension %s is installed; it %s", e.Name, reason),
+				fmt.Sprintf("DROP EXTENSION %s;", quoteIdentAlways(e.Name)))
+		}
+
+		if e.Schema == "public" {
+			add("warning", "EXTENSION_IN_PUBLIC",
+				fmt.Sprintf("Extension %s is installed in schema public, where objects can be shadowed by any role allowed to create there", e.Name),
+				"")
+		}
+
+		if e.DefaultVersion != "" && extensionVersionOlder(e.Version, e.DefaultVersion) && contains(e.AvailableVersions, e.DefaultVersion) {
+			add("warning", "EXTENSION_OUTDATED",
+				fmt.Sprintf("Extension %s is at version %s, version %s is available", e.Name, e.Version, e.DefaultVersion),
+				fmt.Sprintf("ALTER EXTENSION %s UPDATE TO '%s';", quoteIdentAlways(e.Name), e.DefaultVersion))
+		}
+	}
+
+	for _, l := range languages {
+		if l.Trusted {
+			continue
+		}
+
+		severity := "warning"
+		message := fmt.Sprintf("Untrusted language %s is installed", l.Name)
+		if l.Functions > 0 {
+			severity = "high"
+			message = fmt.Sprintf("Untrusted language %s is installed and used by %d function(s)", l.Name, l.Functions)
+		}
+
+		findings = append(findings, Finding{
+			Severity:   severity,
+			Code:       "UNTRUSTED_LANGUAGE",
+			Message:    message,
+			ObjectType: "language",
+			Object:     l.Name,
+		})
+	}
+
+	return findings
+}
+
+// extensionVersionOlder reports whether version a precedes b. Versions are
+// free-form strings, so dot-separated parts are compared numerically by
+// their leading digits and then as text ("1.10" > "1.9", "1.0" > "1.0beta").
+func extensionVersionOlder(a, b string) bool {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, ra := splitVersionPart(pa[i])
+		nb, rb := splitVersionPart(pb[i])
+		if na != nb {
+			return na < nb
+		}
+		if ra != rb {
+			// a release has no suffix and sorts after its pre-releases
+			if ra == "" || rb == "" {
+				return rb == ""
+			}
+			return ra < rb
+		}
+	}
+	return len(pa) < len(pb)
+}
+
+func splitVersionPart(part string) (int, string) {
+	digits := len(part) - len(strings.TrimLeft(part, "0123456789"))
+	n, _ := strconv.Atoi(part[:digits])
+	return n, part[digits:]
+}
@@ -0,0 +1,36 @@
+package checker
+
+import "testing"
+
+func TestExtensionVersionOlder(t *testing.T) {
+	tests := []struct {
+		installed string
+		available string
+		want      bool
+	}{
+		{"1.0", "1.1", true},
+		{"1.1", "1.0", false},
+		{"1.1", "1.1", false},
+		{"1.5.3", "1.10", true},
+		{"1.10", "1.5.3", false},
+		{"1.9", "1.10", true},
+		{"1.0", "1.0.1", true},
+		{"1.0.1", "1.0", false},
+		{"2", "10", true},
+		{"2.0beta", "2.0", true},
+		{"2.0", "2.0beta", false},
+		{"2.0beta", "2.0rc1", true},
+		{"2.0rc1", "2.0beta", false},
+		{"2.0rc1", "2.1beta", true},
+		{"3.4.0dev", "3.3.2", false},
+		{"unpackaged", "unpackaged", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.installed+"_vs_"+tt.available, func(t *testing.T) {
+			if got := extensionVersionOlder(tt.installed, tt.available); got != tt.want {
+				t.Errorf("extensionVersionOlder(%q, %q) = %v, want %v", tt.installed, tt.available, got, tt.want)
+			}
+		})
+	}
+}
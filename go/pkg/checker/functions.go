@@ -0,0 +1,130 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type FunctionInfo struct {
+	Schema          string   `json:"schema"`
+	Name            string   `json:"name"`
+	Signature       string   `json:"signature"`
+	Owner           string   `json:"owner"`
+	OwnerSuperuser  bool     `json:"owner_superuser"`
+	Language        string   `json:"language"`
+	LanguageTrusted bool     `json:"language_trusted"`
+	SecurityDefiner bool     `json:"security_definer"`
+	SearchPath      *string  `json:"search_path,omitempty"`
+	PublicExecute   bool     `json:"public_execute"`
+	Executors       []string `json:"executors"`
+}
+
+// getSecurityDefinerFunctions collects SECURITY DEFINER functions outside
+// the system schemas together with the roles allowed to call them
+func getSecurityDefinerFunctions(ctx context.Context, conn Querier) ([]FunctionInfo, error) {
+	query := `
+		SELECT
+			n.nspname,
+			p.proname,
+			quote_ident(n.nspname) || '.' || quote_ident(p.proname) || '(' || pg_get_function_identity_arguments(p.oid) || ')',
+			o.rolname,
+			o.rolsuper,
+			l.lanname,
+			l.lanpltrusted,
+			p.prosecdef,
+			(SELECT substr(cfg, length('search_path=') + 1)
+			   FROM unnest(p.proconfig) cfg
+			  WHERE cfg LIKE 'search_path=%'),
+			EXISTS (
+				SELECT 1 FROM aclexplode(coalesce(p.proacl, acldefault('f', p.proowner))) a
+				WHERE a.grantee = 0 AND a.privilege_type = 'EXECUTE'
+			),
+			ARRAY(
+				SELECT r.rolname FROM pg_roles r
+				WHERE r.rolname NOT LIKE 'pg_%'
+				  AND has_function_privilege(r.oid, p.oid, 'EXECUTE')
+				ORDER BY r.rolname
+			)
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		JOIN pg_roles o ON o.oid = p.proowner
+		JOIN pg_language l ON l.oid = p.prolang
+		WHERE p.prosecdef
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY n.nspname, p.proname
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var functions []FunctionInfo
+	for rows.Next() {
+		var f FunctionInfo
+		if err := rows.Scan(&f.Schema, &f.Name, &f.Signature, &f.Owner, &f.OwnerSuperuser,
+			&f.Language, &f.LanguageTrusted, &f.SecurityDefiner, &f.SearchPath,
+			&f.PublicExecute, &f.Executors); err != nil {
+			return nil, err
+		}
+		functions = append(functions, f)
+	}
+
+	return functions, rows.Err()
+}
+
+func functionFindings(functions []FunctionInfo) []Finding {
+	var findings []Finding
+
+	for _, fn := range functions {
+		if !fn.SecurityDefiner {
+			continue
+		}
+
+		callers := "no roles"
+		if len(fn.Executors) > 0 {
+			callers = strings.Join(fn.Executors, ", ")
+		}
+
+		add := func(severity, code, message, remediation string) {
+			findings = append(findings, Finding{
+				Severity:    severity,
+				Code:        code,
+				Message:     fmt.Sprintf("%s (callable by: %s)", message, callers),
+				ObjectType:  "function",
+				Object:      fn.Signature,
+				Remediation: fix(remediation),
+			})
+		}
+
+		if fn.SearchPath == nil {
+			add("high", "SECDEF_NO_SEARCH_PATH",
+				fmt.Sprintf("SECURITY DEFINER function %s does not pin search_path", fn.Signature),
+				fmt.Sprintf("ALTER FUNCTION %s SET search_path = pg_catalog, pg_temp;", fn.Signature))
+		}
+
+		if fn.OwnerSuperuser {
+			add("high", "SECDEF_SUPERUSER_OWNER",
+				fmt.Sprintf("SECURITY DEFINER function %s is owned by superuser %s", fn.Signature, fn.Owner),
+				"")
+		}
+
+		if fn.PublicExecute {
+			add("warning", "SECDEF_PUBLIC_EXECUTE",
+				fmt.Sprintf("SECURITY DEFINER function %s is executable by PUBLIC", fn.Signature),
+				fmt.Sprintf("REVOKE EXECUTE ON FUNCTION %s FROM PUBLIC;", fn.Signature))
+		}
+
+		// C and internal functions are untrusted by definition but can only be
+		// created by superusers from shared libraries, so they are not flagged
+		if !fn.LanguageTrusted && fn.Language != "c" && fn.Language != "internal" {
+			add("high", "SECDEF_UNTRUSTED_LANGUAGE",
+				fmt.Sprintf("SECURITY DEFINER function %s is written in untrusted language %s", fn.Signature, fn.Language),
+				"")
+		}
+	}
+
+	return findings
+}
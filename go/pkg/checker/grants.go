@@ -0,0 +1,166 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+)
+
+// Grant is one ACL entry of any object kind. Entries held by the object's
+// owner are not listed; PUBLIC appears as grantee "PUBLIC".
+type Grant struct {
+	Grantor    string `json:"grantor"`
+	Grantee    string `json:"grantee"`
+	ObjectKind string `json:"object_kind"`
+	Object     string `json:"object"`
+	Owner      string `json:"owner"`
+	Privilege  string `json:"privilege"`
+	Grantable  bool   `json:"grantable"`
+}
+
+// grantKeywords maps object kinds to the keyword used in GRANT/REVOKE
+var grantKeywords = map[string]string{
+	"table":                "TABLE",
+	"view":                 "TABLE",
+	"materialized view":    "TABLE",
+	"foreign table":        "TABLE",
+	"sequence":             "SEQUENCE",
+	"function":             "FUNCTION",
+	"procedure":            "PROCEDURE",
+	"schema":               "SCHEMA",
+	"database":             "DATABASE",
+	"tablespace":           "TABLESPACE",
+	"foreign data wrapper": "FOREIGN DATA WRAPPER",
+	"foreign server":       "FOREIGN SERVER",
+	"type":                 "TYPE",
+	"large object":         "LARGE OBJECT",
+}
+
+func getGrants(ctx context.Context, conn Querier) ([]Grant, error) {
+	query := `
+		WITH objects(kind, object, owner, acl) AS (
+			SELECT
+				CASE c.relkind
+					WHEN 'S' THEN 'sequence'
+					WHEN 'v' THEN 'view'
+					WHEN 'm' THEN 'materialized view'
+					WHEN 'f' THEN 'foreign table'
+					ELSE 'table'
+				END,
+				quote_ident(n.nspname) || '.' || quote_ident(c.relname),
+				c.relowner,
+				c.relacl
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind IN ('r', 'p', 'v', 'm', 'f', 'S')
+			  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+
+			UNION ALL
+
+			SELECT
+				CASE p.prokind WHEN 'p' THEN 'procedure' ELSE 'function' END,
+				quote_ident(n.nspname) || '.' || quote_ident(p.proname) || '(' || pg_get_function_identity_arguments(p.oid) || ')',
+				p.proowner,
+				p.proacl
+			FROM pg_proc p
+			JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+
+			UNION ALL
+
+			SELECT 'schema', quote_ident(nspname), nspowner, nspacl
+			FROM pg_namespace
+			WHERE nspname NOT LIKE 'pg_%' AND nspname <> 'information_schema'
+
+			UNION ALL
+
+			SELECT 'database', quote_ident(datname), datdba, datacl
+			FROM pg_database
+
+			UNION ALL
+
+			SELECT 'tablespace', quote_ident(spcname), spcowner, spcacl
+			FROM pg_tablespace
+
+			UNION ALL
+
+			SELECT 'foreign data wrapper', quote_ident(fdwname), fdwowner, fdwacl
+			FROM pg_foreign_data_wrapper
+
+			UNION ALL
+
+			SELECT 'foreign server', quote_ident(srvname), srvowner, srvacl
+			FROM pg_foreign_server
+
+			UNION ALL
+
+			SELECT 'type', quote_ident(n.nspname) || '.' || quote_ident(t.typname), t.typowner, t.typacl
+			FROM pg_type t
+			JOIN pg_namespace n ON n.oid = t.typnamespace
+			WHERE n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+
+			UNION ALL
+
+			SELECT 'large object', oid::text, lomowner, lomacl
+			FROM pg_largeobject_metadata
+		)
+		SELECT
+			o.kind,
+			o.object,
+			pg_get_userbyid(o.owner),
+			pg_get_userbyid(a.grantor),
+			CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee) END,
+			a.privilege_type,
+			a.is_grantable
+		FROM objects o,
+			aclexplode(o.acl) a
+		WHERE o.acl IS NOT NULL
+		  AND a.grantee <> o.owner
+		ORDER BY 1, 2, 5, 6
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []Grant
+	for rows.Next() {
+		var g Grant
+		if err := rows.Scan(&g.ObjectKind, &g.Object, &g.Owner, &g.Grantor,
+			&g.Grantee, &g.Privilege, &g.Grantable); err != nil {
+			return nil, err
+		}
+		grants = append(grants, g)
+	}
+
+	return grants, rows.Err()
+}
+
+func grantFindings(grants []Grant) []Finding {
+	var findings []Finding
+
+	for _, g := range grants {
+		if !g.Grantable || g.Grantee == g.Owner {
+			continue
+		}
+
+		grantee := g.Grantee
+		if grantee != "PUBLIC" {
+			grantee = quoteIdentAlways(grantee)
+		}
+
+		findings = append(findings, Finding{
+			Severity: "warning",
+			Code:     "GRANTABLE_BY_NON_OWNER",
+			Message: fmt.Sprintf("%s holds %s on %s %s WITH GRANT OPTION (granted by %s, owner %s)",
+				g.Grantee, g.Privilege, g.ObjectKind, g.Object, g.Grantor, g.Owner),
+			ObjectType: g.ObjectKind,
+			Object:     g.Object,
+			Remediation: fix(fmt.Sprintf("REVOKE GRANT OPTION FOR %s ON %s %s FROM %s;",
+				g.Privilege, grantKeywords[g.ObjectKind], g.Object, grantee)),
+		})
+	}
+
+	return findings
+}
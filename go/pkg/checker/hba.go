@@ -0,0 +1,194 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+type HBARule struct {
+	LineNumber int      `json:"line_number"`
+	Type       string   `json:"type"`
+	Databases  []string `json:"databases"`
+	Users      []string `json:"users"`
+	Address    string   `json:"address,omitempty"`
+	Netmask    string   `json:"netmask,omitempty"`
+	AuthMethod string   `json:"auth_method"`
+	Error      string   `json:"error,omitempty"`
+}
+
+func getHBARules(ctx context.Context, conn Querier) ([]HBARule, error) {
+	query := `
+		SELECT
+			line_number,
+			coalesce(type, ''),
+			coalesce(database, '{}'),
+			coalesce(user_name, '{}'),
+			coalesce(address, ''),
+			coalesce(netmask, ''),
+			coalesce(auth_method, ''),
+			coalesce(error, '')
+		FROM pg_hba_file_rules
+		ORDER BY line_number
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []HBARule
+	for rows.Next() {
+		var r HBARule
+		if err := rows.Scan(&r.LineNumber, &r.Type, &r.Databases, &r.Users,
+			&r.Address, &r.Netmask, &r.AuthMethod, &r.Error); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, rows.Err()
+}
+
+func hbaFindings(rules []HBARule) []Finding {
+	var findings []Finding
+
+	for _, r := range rules {
+		object := fmt.Sprintf("pg_hba.conf:%d", r.LineNumber)
+		add := func(severity, code, message string) {
+			findings = append(findings, Finding{
+				Severity:   severity,
+				Code:       code,
+				Message:    fmt.Sprintf("pg_hba.conf line %d: %s", r.LineNumber, message),
+				ObjectType: "hba_rule",
+				Object:     object,
+			})
+		}
+
+		if r.Error != "" {
+			add("warning", "HBA_PARSE_ERROR", fmt.Sprintf("rule cannot be parsed (%s)", r.Error))
+			continue
+		}
+
+		if r.AuthMethod == "reject" {
+			continue
+		}
+
+		remote := r.Type != "local" && !isLoopback(r.Address, r.Netmask)
+
+		switch r.AuthMethod {
+		case "trust":
+			severity := "high"
+			if remote {
+				severity = "critical"
+			}
+			add(severity, "HBA_TRUST", fmt.Sprintf("%s connections for %s use trust authentication", r.Type, describeHBATarget(r)))
+		case "password":
+			add("high", "HBA_PASSWORD", fmt.Sprintf("%s connections for %s send passwords in clear text (method password)", r.Type, describeHBATarget(r)))
+		}
+
+		if !remote {
+			continue
+		}
+
+		if contains(r.Databases, "all") && contains(r.Users, "all") && isAnyAddress(r.Address, r.Netmask) {
+			add("high", "HBA_OPEN_WORLD", fmt.Sprintf("%s all all is open to every address", r.Type))
+		}
+
+		// hostnogssenc only excludes GSSAPI encryption and still accepts plain TCP
+		if r.Type == "host" || r.Type == "hostnossl" || r.Type == "hostnogssenc" {
+			add("warning", "HBA_NO_SSL", fmt.Sprintf("%s rule for %s from %s does not require SSL", r.Type, describeHBATarget(r), describeHBAAddress(r)))
+		}
+
+		if contains(r.Databases, "replication") && isWideNetwork(r.Address, r.Netmask) {
+			add("high", "HBA_REPLICATION_WIDE", fmt.Sprintf("replication connections allowed from wide network %s", describeHBAAddress(r)))
+		}
+	}
+
+	return findings
+}
+
+func describeHBATarget(r HBARule) string {
+	return fmt.Sprintf("database %s user %s", strings.Join(r.Databases, ","), strings.Join(r.Users, ","))
+}
+
+func describeHBAAddress(r HBARule) string {
+	if r.Netmask == "" {
+		return r.Address
+	}
+	if ones, ok := prefixLength(r.Address, r.Netmask); ok {
+		return fmt.Sprintf("%s/%d", r.Address, ones)
+	}
+	return r.Address + " " + r.Netmask
+}
+
+func prefixLength(address, netmask string) (int, bool) {
+	if net.ParseIP(address) == nil {
+		return 0, false
+	}
+	mask := net.ParseIP(netmask)
+	if mask == nil {
+		return 0, false
+	}
+	if v4 := mask.To4(); v4 != nil && net.ParseIP(address).To4() != nil {
+		mask = v4
+	}
+	ones, bits := net.IPMask(mask).Size()
+	if bits == 0 {
+		return 0, false
+	}
+	return ones, true
+}
+
+func isLoopback(address, netmask string) bool {
+	switch address {
+	case "samehost", "localhost":
+		return true
+	}
+	ip := net.ParseIP(address)
+	if ip == nil || !ip.IsLoopback() {
+		return false
+	}
+	ones, ok := prefixLength(address, netmask)
+	if !ok {
+		return true
+	}
+	if ip.To4() != nil {
+		return ones >= 8
+	}
+	return ones == 128
+}
+
+func isAnyAddress(address, netmask string) bool {
+	if address == "" || address == "all" {
+		return true
+	}
+	ones, ok := prefixLength(address, netmask)
+	return ok && ones == 0
+}
+
+// isWideNetwork treats anything larger than a /16 (IPv4) or /48 (IPv6) as wide
+func isWideNetwork(address, netmask string) bool {
+	if isAnyAddress(address, netmask) || address == "samenet" {
+		return true
+	}
+	ones, ok := prefixLength(address, netmask)
+	if !ok {
+		return false
+	}
+	if net.ParseIP(address).To4() != nil {
+		return ones < 16
+	}
+	return ones < 48
+}
+
+func contains(list []string, item string) bool {
+	for _, s := range list {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
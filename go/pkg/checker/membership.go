@@ -0,0 +1,210 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Membership is one pg_auth_members row seen from the member's side
+type Membership struct {
+	Role    string `json:"role"`
+	Grantor string `json:"grantor,omitempty"`
+	Admin   bool   `json:"admin_option"`
+	Inherit bool   `json:"inherit_option"`
+	Set     bool   `json:"set_option"`
+}
+
+// privilegedRoles are predefined roles that allow reading or writing server
+// files or running programs, which is equivalent to superuser in practice
+var privilegedRoles = map[string]string{
+	"pg_execute_server_program": "critical",
+	"pg_write_server_files":     "critical",
+	"pg_read_server_files":      "high",
+}
+
+func getMemberships(ctx context.Context, conn Querier, roles []RoleInfo) error {
+	memberships, err := queryMemberships(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for i := range roles {
+		if m, ok := memberships[roles[i].Name]; ok {
+			roles[i].MemberOf = m
+		}
+	}
+
+	graph := NewRoleGraph(roles)
+	for i := range roles {
+		roles[i].EffectiveRoles = graph.Reachable(roles[i].Name, false)
+	}
+
+	return nil
+}
+
+// queryMemberships returns pg_auth_members grouped by member role name
+func queryMemberships(ctx context.Context, conn Querier) (map[string][]Membership, error) {
+	var versionNum int
+	if err := conn.QueryRow(ctx, "SELECT current_setting('server_version_num')::int").Scan(&versionNum); err != nil {
+		return nil, err
+	}
+
+	// PostgreSQL 16 moved INHERIT and SET from role attributes to each grant
+	options := "am.admin_option, m.rolinherit, true"
+	if versionNum >= 160000 {
+		options = "am.admin_option, am.inherit_option, am.set_option"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			m.rolname,
+			r.rolname,
+			coalesce(g.rolname, ''),
+			%s
+		FROM pg_auth_members am
+		JOIN pg_roles r ON r.oid = am.roleid
+		JOIN pg_roles m ON m.oid = am.member
+		LEFT JOIN pg_roles g ON g.oid = am.grantor
+		ORDER BY m.rolname, r.rolname
+	`, options)
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := make(map[string][]Membership)
+	for rows.Next() {
+		var member string
+		var m Membership
+		if err := rows.Scan(&member, &m.Role, &m.Grantor, &m.Admin, &m.Inherit, &m.Set); err != nil {
+			return nil, err
+		}
+		memberships[member] = append(memberships[member], m)
+	}
+
+	return memberships, rows.Err()
+}
+
+// LoadRoleGraph reads the membership graph of every role in the cluster,
+// including predefined pg_* roles
+func LoadRoleGraph(ctx context.Context, conn *pgx.Conn) (*RoleGraph, error) {
+	memberships, err := queryMemberships(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return &RoleGraph{edges: memberships}, nil
+}
+
+// RoleGraph is a directed graph from member roles to the roles they belong to
+type RoleGraph struct {
+	edges map[string][]Membership
+}
+
+func NewRoleGraph(roles []RoleInfo) *RoleGraph {
+	g := &RoleGraph{edges: make(map[string][]Membership)}
+	for _, r := range roles {
+		g.edges[r.Name] = r.MemberOf
+	}
+	return g
+}
+
+// Paths returns, for every role reachable from the given role, the shortest
+// membership chain leading to it (starting with the role itself). With
+// inheritOnly set only INHERIT edges are followed, i.e. privileges the role
+// uses without SET ROLE; otherwise any edge with INHERIT or SET counts.
+func (g *RoleGraph) Paths(from string, inheritOnly bool) map[string][]string {
+	paths := map[string][]string{}
+	queue := []string{from}
+	parent := map[string]string{from: ""}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for _, m := range g.edges[cur] {
+			usable := m.Inherit || (!inheritOnly && m.Set)
+			if !usable {
+				continue
+			}
+			if _, seen := parent[m.Role]; seen {
+				continue
+			}
+			parent[m.Role] = cur
+			queue = append(queue, m.Role)
+
+			var chain []string
+			for r := m.Role; r != ""; r = parent[r] {
+				chain = append([]string{r}, chain...)
+			}
+			paths[m.Role] = chain
+		}
+	}
+
+	return paths
+}
+
+// Reachable returns the sorted transitive closure of roles reachable from name
+func (g *RoleGraph) Reachable(name string, inheritOnly bool) []string {
+	reachable := []string{}
+	for r := range g.Paths(name, inheritOnly) {
+		reachable = append(reachable, r)
+	}
+	sort.Strings(reachable)
+	return reachable
+}
+
+func membershipFindings(roles []RoleInfo) []Finding {
+	var findings []Finding
+
+	superusers := make(map[string]bool)
+	for _, r := range roles {
+		if r.Superuser {
+			superusers[r.Name] = true
+		}
+	}
+
+	graph := NewRoleGraph(roles)
+	for _, role := range roles {
+		if !role.Login {
+			continue
+		}
+
+		paths := graph.Paths(role.Name, false)
+		for _, target := range sortedPathTargets(paths) {
+			severity, privileged := privilegedRoles[target]
+			if superusers[target] {
+				severity, privileged = "critical", true
+			}
+			if !privileged {
+				continue
+			}
+
+			findings = append(findings, Finding{
+				Severity:   severity,
+				Code:       "ROLE_REACHES_PRIVILEGED",
+				Message:    fmt.Sprintf("Login role %s can reach superuser-equivalent role %s via %s", role.Name, target, strings.Join(paths[target], " -> ")),
+				ObjectType: "role",
+				Object:     role.Name,
+				Remediation: fix(fmt.Sprintf("REVOKE %s FROM %s;",
+					quoteIdentAlways(paths[target][1]), quoteIdentAlways(role.Name))),
+			})
+		}
+	}
+
+	return findings
+}
+
+func sortedPathTargets(paths map[string][]string) []string {
+	targets := make([]string, 0, len(paths))
+	for t := range paths {
+		targets = append(targets, t)
+	}
+	sort.Strings(targets)
+	return targets
+}
@@ -0,0 +1,190 @@
+package checker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PasswordInfo holds password metadata for a role. The password hash itself
+// is never stored in the report.
+type PasswordInfo struct {
+	Set            bool       `json:"set"`
+	Method         string     `json:"method,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	Expired        bool       `json:"expired"`
+	ActiveSessions int        `json:"active_sessions"`
+	EqualsName     bool       `json:"equals_name"`
+}
+
+// getPasswordInfo reads password metadata by role name from pg_authid, which
+// only superusers can read
+func getPasswordInfo(ctx context.Context, conn Querier) (map[string]*PasswordInfo, error) {
+	query := `
+		SELECT
+			a.rolname,
+			a.rolpassword,
+			CASE WHEN a.rolvaliduntil = 'infinity' THEN NULL ELSE a.rolvaliduntil END,
+			coalesce(a.rolvaliduntil < now(), false),
+			(SELECT count(*) FROM pg_stat_activity s WHERE s.usename = a.rolname)
+		FROM pg_authid a
+		WHERE a.rolname NOT LIKE 'pg_%'
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byName := make(map[string]*PasswordInfo)
+	for rows.Next() {
+		var name string
+		var hash *string
+		var info PasswordInfo
+		if err := rows.Scan(&name, &hash, &info.ValidUntil, &info.Expired, &info.ActiveSessions); err != nil {
+			return nil, err
+		}
+
+		if hash != nil && *hash != "" {
+			info.Set = true
+			info.Method = passwordMethod(*hash)
+			info.EqualsName = passwordMatches(*hash, name, name)
+		}
+		byName[name] = &info
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return byName, nil
+}
+
+func passwordMethod(hash string) string {
+	switch {
+	case strings.HasPrefix(hash, "SCRAM-SHA-256$"):
+		return "scram-sha-256"
+	case strings.HasPrefix(hash, "md5") && len(hash) == 35:
+		return "md5"
+	default:
+		return "plain"
+	}
+}
+
+// passwordMatches reports whether password produces the stored hash
+func passwordMatches(hash, password, roleName string) bool {
+	switch passwordMethod(hash) {
+	case "scram-sha-256":
+		return scramMatches(hash, password)
+	case "md5":
+		sum := md5.Sum([]byte(password + roleName))
+		return hmac.Equal([]byte(hash), []byte("md5"+hex.EncodeToString(sum[:])))
+	default:
+		return hmac.Equal([]byte(hash), []byte(password))
+	}
+}
+
+// scramMatches verifies password against a SCRAM-SHA-256 verifier in the
+// format SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+func scramMatches(verifier, password string) bool {
+	parts := strings.Split(strings.TrimPrefix(verifier, "SCRAM-SHA-256$"), "$")
+	if len(parts) != 2 {
+		return false
+	}
+
+	iterSalt := strings.SplitN(parts[0], ":", 2)
+	keys := strings.SplitN(parts[1], ":", 2)
+	if len(iterSalt) != 2 || len(keys) != 2 {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(iterSalt[0])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(iterSalt[1])
+	if err != nil {
+		return false
+	}
+	storedKey, err := base64.StdEncoding.DecodeString(keys[0])
+	if err != nil {
+		return false
+	}
+
+	salted, err := pbkdf2.Key(sha256.New, password, salt, iterations, sha256.Size)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, salted)
+	mac.Write([]byte("Client Key"))
+	clientKey := mac.Sum(nil)
+	computed := sha256.Sum256(clientKey)
+
+	return hmac.Equal(computed[:], storedKey)
+}
+
+func passwordFindings(report *Report) []Finding {
+	var findings []Finding
+
+	scramDefault := strings.EqualFold(report.Instance.Settings["password_encryption"], "scram-sha-256")
+
+	for _, role := range report.Roles {
+		pw := role.Password
+		if pw == nil {
+			continue
+		}
+
+		add := func(severity, code, message, remediation string) {
+			findings = append(findings, Finding{
+				Severity:    severity,
+				Code:        code,
+				Message:     message,
+				ObjectType:  "role",
+				Object:      role.Name,
+				Remediation: fix(remediation),
+			})
+		}
+
+		if role.Login && !pw.Set {
+			add("warning", "LOGIN_NO_PASSWORD",
+				fmt.Sprintf("Login role %s has no password; access depends entirely on pg_hba.conf", role.Name),
+				"")
+		}
+
+		if pw.EqualsName {
+			add("critical", "PASSWORD_EQUALS_NAME",
+				fmt.Sprintf("Role %s uses its own name as password", role.Name),
+				"")
+		}
+
+		if pw.Method == "md5" && scramDefault {
+			add("warning", "PASSWORD_MD5",
+				fmt.Sprintf("Role %s still has an MD5 password while password_encryption is scram-sha-256", role.Name),
+				"")
+		}
+
+		if role.Login && pw.Set && pw.ValidUntil == nil {
+			add("info", "PASSWORD_NO_EXPIRY",
+				fmt.Sprintf("Login role %s has a password without VALID UNTIL", role.Name),
+				"")
+		}
+
+		if pw.Expired && pw.ActiveSessions > 0 {
+			add("warning", "PASSWORD_EXPIRED_IN_USE",
+				fmt.Sprintf("Role %s has an expired password but %d active session(s)", role.Name, pw.ActiveSessions),
+				fmt.Sprintf("SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE usename = %s;", quoteLiteral(role.Name)))
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,69 @@
+package checker
+
+import "testing"
+
+// Generated for password "app_user" with salt "pg-sec-lab-salt!" and 4096 iterations
+const testSCRAMVerifier = "SCRAM-SHA-256$4096:cGctc2VjLWxhYi1zYWx0IQ==$ZpOO9Q8fzJUFq4Uoskde5I1f0MG9UTYnXLHC3F++tLI=:3j8xZ+xsJbXUzgegBqgnM5ixAfwWaAJKuazr4Gsmi8E="
+
+func TestScramMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier string
+		password string
+		want     bool
+	}{
+		{"matching password", testSCRAMVerifier, "app_user", true},
+		{"wrong password", testSCRAMVerifier, "app_user2", false},
+		{"empty password", testSCRAMVerifier, "", false},
+		{"missing keys", "SCRAM-SHA-256$4096:cGctc2VjLWxhYi1zYWx0IQ==", "app_user", false},
+		{"bad iterations", "SCRAM-SHA-256$x:cGctc2VjLWxhYi1zYWx0IQ==$ZpOO9Q8fzJUFq4Uoskde5I1f0MG9UTYnXLHC3F++tLI=:3j8xZ+xsJbXUzgegBqgnM5ixAfwWaAJKuazr4Gsmi8E=", "app_user", false},
+		{"bad salt", "SCRAM-SHA-256$4096:***$ZpOO9Q8fzJUFq4Uoskde5I1f0MG9UTYnXLHC3F++tLI=:3j8xZ+xsJbXUzgegBqgnM5ixAfwWaAJKuazr4Gsmi8E=", "app_user", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scramMatches(tt.verifier, tt.password); got != tt.want {
+				t.Errorf("scramMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		role     string
+		want     bool
+	}{
+		{"scram equals name", testSCRAMVerifier, "app_user", "app_user", true},
+		{"scram differs", testSCRAMVerifier, "other", "app_user", false},
+		{"md5 equals name", "md5243179a7619ef5a044e0eb7ef2845e0e", "app_user", "app_user", true},
+		{"md5 salted with other role", "md5243179a7619ef5a044e0eb7ef2845e0e", "app_user", "other", false},
+		{"plain", "secret", "secret", "app_user", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := passwordMatches(tt.hash, tt.password, tt.role); got != tt.want {
+				t.Errorf("passwordMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordMethod(t *testing.T) {
+	tests := map[string]string{
+		testSCRAMVerifier:                     "scram-sha-256",
+		"md5243179a7619ef5a044e0eb7ef2845e0e": "md5",
+		"md5short":                            "plain",
+		"secret":                              "plain",
+	}
+
+	for hash, want := range tests {
+		if got := passwordMethod(hash); got != want {
+			t.Errorf("passwordMethod(%q) = %q, want %q", hash, got, want)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxPIISampleRows bounds the rows read per table when sampling is enabled
+const maxPIISampleRows = 1000
+
+// PIIColumn is a column classified as holding personal data. Sampled values
+// are only counted, never stored in the report.
+type PIIColumn struct {
+	Schema     string   `json:"schema"`
+	Table      string   `json:"table"`
+	Column     string   `json:"column"`
+	DataType   string   `json:"data_type"`
+	Categories []string `json:"categories"`
+	Sources    []string `json:"sources"`
+	Masked     bool     `json:"masked"`
+}
+
+// piiNamePatterns match lower-cased column names
+var piiNamePatterns = []struct {
+	category string
+	re       *regexp.Regexp
+}{
+	{"email", regexp.MustCompile(`e_?mail`)},
+	{"phone", regexp.MustCompile(`phone|mobile|msisdn|^tel$|^tel_|_tel$`)},
+	{"card_number", regexp.MustCompile(`card_?(number|num|no)$|^pan$|cc_?num|credit_?card`)},
+	{"iban", regexp.MustCompile(`iban|bank_?account|account_?number`)},
+	{"passport", regexp.MustCompile(`passport`)},
+	{"snils", regexp.MustCompile(`snils`)},
+	{"inn", regexp.MustCompile(`^inn$|^inn_|_inn$|tax_?id|taxpayer`)},
+	{"national_id", regexp.MustCompile(`ssn$|national_?id|social_?security`)},
+	{"birth_date", regexp.MustCompile(`birth|^dob$|_dob$`)},
+	{"person_name", regexp.MustCompile(`^(first|last|middle|full|given|sur|family)_?name$|^surname$|^patronymic$`)},
+	{"address", regexp.MustCompile(`^(home_|postal_|mailing_|billing_|shipping_|legal_)?address|street|postal_?code|zip_?code|^zip$`)},
+	{"ip_address", regexp.MustCompile(`^ip$|ip_?addr|client_?ip|remote_?addr`)},
+}
+
+// piiCommentKeywords match lower-cased column comments
+var piiCommentKeywords = map[string][]string{
+	"email":         {"email", "e-mail"},
+	"phone":         {"phone", "телефон"},
+	"card_number":   {"card number", "номер карты"},
+	"passport":      {"passport", "паспорт"},
+	"snils":         {"снилс", "snils"},
+	"inn":           {"инн", "taxpayer"},
+	"personal_data": {"pii", "personal data", "персональн"},
+}
+
+var piiTypes = map[string]string{
+	"inet": "ip_address",
+	"cidr": "ip_address",
+}
+
+// piiSampleTypes are the column types whose values are sampled
+var piiSampleTypes = []string{"text", "character varying", "character", "citext", "bigint", "numeric"}
+
+// getPIIColumns classifies the columns of the current database. skipped
+// lists the tables that could not be sampled.
+func getPIIColumns(ctx context.Context, conn Querier, sampleRows int, masked []MaskedColumn) (columns []PIIColumn, skipped []string, err error) {
+	query := `
+		SELECT
+			n.nspname,
+			c.relname,
+			a.attname,
+			format_type(a.atttypid, NULL),
+			coalesce(col_description(c.oid, a.attnum), '')
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p')
+		  AND a.attnum > 0
+		  AND NOT a.attisdropped
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+		ORDER BY n.nspname, c.relname, a.attnum
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var all []PIIColumn
+	for rows.Next() {
+		var col PIIColumn
+		var comment string
+		if err := rows.Scan(&col.Schema, &col.Table, &col.Column, &col.DataType, &comment); err != nil {
+			return nil, nil, err
+		}
+		classifyColumn(&col, comment)
+		all = append(all, col)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	rows.Close()
+
+	if sampleRows > 0 {
+		if sampleRows > maxPIISampleRows {
+			sampleRows = maxPIISampleRows
+		}
+		skipped = samplePIIColumns(ctx, conn, all, sampleRows)
+	}
+
+	columns = []PIIColumn{}
+	for _, col := range all {
+		if len(col.Categories) == 0 {
+			continue
+		}
+		sort.Strings(col.Categories)
+		for _, m := range masked {
+			schema, table := splitQualified(m.Table)
+			if schema == col.Schema && table == col.Table && m.Column == col.Column {
+				col.Masked = true
+			}
+		}
+		columns = append(columns, col)
+	}
+
+	return columns, skipped, nil
+}
+
+func classifyColumn(col *PIIColumn, comment string) {
+	name := strings.ToLower(col.Column)
+	for _, p := range piiNamePatterns {
+		if p.re.MatchString(name) {
+			col.addCategory(p.category, "name")
+		}
+	}
+
+	if category, ok := piiTypes[col.DataType]; ok {
+		col.addCategory(category, "type")
+	}
+
+	comment = strings.ToLower(comment)
+	for category, keywords := range piiCommentKeywords {
+		for _, k := range keywords {
+			if strings.Contains(comment, k) {
+				col.addCategory(category, "comment")
+				break
+			}
+		}
+	}
+}
+
+func (c *PIIColumn) addCategory(category, source string) {
+	if !contains(c.Categories, category) {
+		c.Categories = append(c.Categories, category)
+	}
+	if !contains(c.Sources, source) {
+		c.Sources = append(c.Sources, source)
+	}
+}
+
+// samplePIIColumns reads up to limit rows of each table and classifies
+// columns where most non-null values match one detector. Tables the current
+// role cannot read are skipped and returned.
+func samplePIIColumns(ctx context.Context, conn Querier, columns []PIIColumn, limit int) []string {
+	byTable := make(map[string][]int)
+	var tables []string
+	for i, col := range columns {
+		if !contains(piiSampleTypes, col.DataType) {
+			continue
+		}
+		key := quoteQualified(col.Schema, col.Table)
+		if _, ok := byTable[key]; !ok {
+			tables = append(tables, key)
+		}
+		byTable[key] = append(byTable[key], i)
+	}
+
+	var skipped []string
+	for _, table := range tables {
+		idx := byTable[table]
+		exprs := make([]string, len(idx))
+		for j, i := range idx {
+			exprs[j] = quoteIdentAlways(columns[i].Column) + "::text"
+		}
+
+		query := fmt.Sprintf("SELECT %s FROM %s LIMIT %d", strings.Join(exprs, ", "), table, limit)
+		rows, err := conn.Query(ctx, query)
+		if err != nil {
+			skipped = append(skipped, table)
+			continue
+		}
+
+		nonNull := make([]int, len(idx))
+		matches := make([]map[string]int, len(idx))
+		for j := range matches {
+			matches[j] = make(map[string]int)
+		}
+
+		values := make([]*string, len(idx))
+		dest := make([]any, len(idx))
+		for j := range values {
+			dest[j] = &values[j]
+		}
+
+		for rows.Next() {
+			if err := rows.Scan(dest...); err != nil {
+				break
+			}
+			for j, v := range values {
+				if v == nil {
+					continue
+				}
+				nonNull[j]++
+				if category := detectPII(*v); category != "" {
+					matches[j][category]++
+				}
+			}
+		}
+		rows.Close()
+		if rows.Err() != nil {
+			skipped = append(skipped, table)
+			continue
+		}
+
+		for j, i := range idx {
+			for category, n := range matches[j] {
+				if n*2 >= nonNull[j] {
+					columns[i].addCategory(category, "sample")
+				}
+			}
+		}
+	}
+
+	return skipped
+}
+
+var (
+	emailRe    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	phoneRe    = regexp.MustCompile(`^(\+\d{1,3}|8)[\s(-]*\d{3}[\s)-]*\d{3}[\s-]*\d{2}[\s-]*\d{2}$|^\+\d{10,15}$`)
+	ibanRe     = regexp.MustCompile(`^[A-Z]{2}\d{2}[A-Z0-9]{11,30}$`)
+	passportRe = regexp.MustCompile(`^\d{2}\s?\d{2}\s\d{6}$`)
+	digitsRe   = regexp.MustCompile(`^\d+$`)
+)
+
+// detectPII returns the category a single value looks like, or ""
+func detectPII(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return ""
+	}
+
+	if emailRe.MatchString(v) {
+		return "email"
+	}
+
+	compact := strings.NewReplacer(" ", "", "-", "").Replace(v)
+	if digitsRe.MatchString(compact) {
+		switch {
+		case len(compact) == 11 && validSNILS(compact):
+			return "snils"
+		case (len(compact) == 10 || len(compact) == 12) && validINN(compact):
+			return "inn"
+		case len(compact) >= 13 && len(compact) <= 19 && validLuhn(compact):
+			return "card_number"
+		}
+	}
+
+	if passportRe.MatchString(v) {
+		return "passport"
+	}
+
+	if phoneRe.MatchString(v) {
+		return "phone"
+	}
+
+	if iban := strings.ToUpper(strings.ReplaceAll(v, " ", "")); ibanRe.MatchString(iban) && validIBAN(iban) {
+		return "iban"
+	}
+
+	return ""
+}
+
+func validLuhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func validIBAN(iban string) bool {
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+	for _, r := range rearranged {
+		var n int
+		switch {
+		case r >= '0' && r <= '9':
+			n = int(r - '0')
+			remainder = (remainder*10 + n) % 97
+		case r >= 'A' && r <= 'Z':
+			n = int(r-'A') + 10
+			remainder = (remainder*100 + n) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
+
+// validSNILS checks the control number of an 11-digit SNILS
+func validSNILS(digits string) bool {
+	if len(digits) != 11 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(digits[i]-'0') * (9 - i)
+	}
+	check := sum % 101
+	if check == 100 {
+		check = 0
+	}
+	return check == int(digits[9]-'0')*10+int(digits[10]-'0')
+}
+
+// validINN checks the control digits of a 10- or 12-digit INN
+func validINN(digits string) bool {
+	checkDigit := func(weights []int) int {
+		sum := 0
+		for i, w := range weights {
+			sum += int(digits[i]-'0') * w
+		}
+		return sum % 11 % 10
+	}
+
+	switch len(digits) {
+	case 10:
+		return checkDigit([]int{2, 4, 10, 3, 5, 9, 4, 6, 8}) == int(digits[9]-'0')
+	case 12:
+		return checkDigit([]int{7, 2, 4, 10, 3, 5, 9, 4, 6, 8}) == int(digits[10]-'0') &&
+			checkDigit([]int{3, 7, 2, 4, 10, 3, 5, 9, 4, 6, 8}) == int(digits[11]-'0')
+	}
+	return false
+}
+
+func piiFindings(columns []PIIColumn, roles []RoleInfo, public []PublicGrant) []Finding {
+	var findings []Finding
+
+	graph := NewRoleGraph(roles)
+	byName := make(map[string]RoleInfo)
+	for _, role := range roles {
+		byName[role.Name] = role
+	}
+
+	for _, col := range columns {
+		if col.Masked {
+			// Covered by MASKED_COLUMN_EXPOSED
+			continue
+		}
+
+		table := col.Schema + "." + col.Table
+		readers := columnReaders(table, col.Column, roles, byName, graph, public)
+		if len(readers) == 0 {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Severity: "high",
+			Code:     "PII_COLUMN_UNMASKED",
+			Message: fmt.Sprintf("Column %s.%s looks like %s (by %s) and is readable by %s without a mask in policy.yaml",
+				table, col.Column, strings.Join(col.Categories, ", "), strings.Join(col.Sources, ", "), strings.Join(readers, ", ")),
+			ObjectType: "column",
+			Object:     table + "." + col.Column,
+		})
+	}
+
+	return findings
+}
+
+// columnReaders returns the non-superuser roles that can read the column
+// with their own grants or those of any role they can use (INHERIT or SET
+// ROLE), including pg_read_all_data. SELECT for PUBLIC is reported as PUBLIC
+// instead of listing every role.
+func columnReaders(table, column string, roles []RoleInfo, byName map[string]RoleInfo, graph *RoleGraph, public []PublicGrant) []string {
+	for _, g := range public {
+		if g.ObjectType == "table" && g.Privilege == "SELECT" && unquoteQualified(g.Object) == table {
+			return []string{"PUBLIC"}
+		}
+	}
+
+	var readers []string
+	for _, role := range roles {
+		if role.Superuser {
+			continue
+		}
+		if canSelectColumn(role, table, column) {
+			readers = append(readers, role.Name)
+			continue
+		}
+		for _, name := range graph.Reachable(role.Name, false) {
+			member, ok := byName[name]
+			if name == "pg_read_all_data" || ok && canSelectColumn(member, table, column) {
+				readers = append(readers, role.Name)
+				break
+			}
+		}
+	}
+
+	return readers
+}
+
+func canSelectColumn(role RoleInfo, table, column string) bool {
+	if contains(role.Grants, "SELECT ON "+table) {
+		return true
+	}
+	for _, cg := range role.ColumnGrants {
+		if cg.Object == table && cg.Column == column && cg.Privilege == "SELECT" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package checker
+
+import "testing"
+
+func TestDetectPII(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"user@example.com", "email"},
+		{"  user@example.com ", "email"},
+		{"user@localhost", ""},
+		{"+7 (912) 345-67-89", "phone"},
+		{"89123456789", "phone"},
+		{"+4915123456789", "phone"},
+		{"4111 1111 1111 1111", "card_number"},
+		{"4111-1111-1111-1112", ""},
+		{"GB82 WEST 1234 5698 7654 32", "iban"},
+		{"GB82WEST12345698765433", ""},
+		{"45 07 123456", "passport"},
+		{"112-233-445 95", "snils"},
+		{"7707083893", "inn"},
+		{"500100732259", "inn"},
+		{"hello world", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := detectPII(tt.value); got != tt.want {
+				t.Errorf("detectPII(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidLuhn(t *testing.T) {
+	tests := map[string]bool{
+		"4111111111111111": true,
+		"5555555555554444": true,
+		"4111111111111112": false,
+		"1234567812345678": false,
+	}
+
+	for digits, want := range tests {
+		if got := validLuhn(digits); got != want {
+			t.Errorf("validLuhn(%q) = %v, want %v", digits, got, want)
+		}
+	}
+}
+
+func TestValidIBAN(t *testing.T) {
+	tests := map[string]bool{
+		"GB82WEST12345698765432": true,
+		"DE89370400440532013000": true,
+		"GB82WEST12345698765433": false,
+		"DE89370400440532013001": false,
+		"GB82WEST1234569876543!": false,
+	}
+
+	for iban, want := range tests {
+		if got := validIBAN(iban); got != want {
+			t.Errorf("validIBAN(%q) = %v, want %v", iban, got, want)
+		}
+	}
+}
+
+func TestValidSNILS(t *testing.T) {
+	tests := map[string]bool{
+		"11223344595": true,
+		"11223344596": false,
+		"1122334459":  false,
+	}
+
+	for digits, want := range tests {
+		if got := validSNILS(digits); got != want {
+			t.Errorf("validSNILS(%q) = %v, want %v", digits, got, want)
+		}
+	}
+}
+
+func TestValidINN(t *testing.T) {
+	tests := map[string]bool{
+		"7707083893":   true,
+		"7707083894":   false,
+		"500100732259": true,
+		"500100732258": false,
+		"50010073225":  false,
+	}
+
+	for digits, want := range tests {
+		if got := validINN(digits); got != want {
+			t.Errorf("validINN(%q) = %v, want %v", digits, got, want)
+		}
+	}
+}
+
+func TestPIIFindingsReaders(t *testing.T) {
+	columns := []PIIColumn{{Schema: "public", Table: "customers", Column: "email", Categories: []string{"email"}, Sources: []string{"name"}}}
+
+	tests := []struct {
+		name   string
+		roles  []RoleInfo
+		public []PublicGrant
+		want   string
+	}{
+		{
+			name:  "direct grant",
+			roles: []RoleInfo{{Name: "analyst", Grants: []string{"SELECT ON public.customers"}}},
+			want:  "analyst",
+		},
+		{
+			name: "inherited through membership",
+			roles: []RoleInfo{
+				{Name: "readers", Grants: []string{"SELECT ON public.customers"}},
+				{Name: "app", MemberOf: []Membership{{Role: "readers", Inherit: true}}},
+			},
+			want: "readers, app",
+		},
+		{
+			name: "column grant reachable with SET ROLE",
+			roles: []RoleInfo{
+				{Name: "support", ColumnGrants: []ColumnGrant{{Object: "public.customers", Column: "email", Privilege: "SELECT"}}},
+				{Name: "app", MemberOf: []Membership{{Role: "support", Set: true}}},
+			},
+			want: "support, app",
+		},
+		{
+			name:  "pg_read_all_data",
+			roles: []RoleInfo{{Name: "etl", MemberOf: []Membership{{Role: "pg_read_all_data", Inherit: true}}}},
+			want:  "etl",
+		},
+		{
+			name:   "PUBLIC",
+			roles:  []RoleInfo{{Name: "app"}},
+			public: []PublicGrant{{ObjectType: "table", Object: "public.customers", Privilege: "SELECT"}},
+			want:   "PUBLIC",
+		},
+		{
+			name:  "superuser and other tables",
+			roles: []RoleInfo{{Name: "postgres", Superuser: true}, {Name: "app", Grants: []string{"SELECT ON public.orders"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := piiFindings(columns, tt.roles, tt.public)
+			if tt.want == "" {
+				if len(findings) != 0 {
+					t.Fatalf("expected no findings, got %v", findings)
+				}
+				return
+			}
+			if len(findings) != 1 {
+				t.Fatalf("expected one finding, got %v", findings)
+			}
+			want := "Column public.customers.email looks like email (by name) and is readable by " + tt.want + " without a mask in policy.yaml"
+			if findings[0].Message != want {
+				t.Errorf("message = %q, want %q", findings[0].Message, want)
+			}
+		})
+	}
+}
+
+func TestUnquoteQualified(t *testing.T) {
+	tests := map[string]string{
+		"public.customers":      "public.customers",
+		`"Sales"."Order.Items"`: "Sales.Order.Items",
+		`public."say ""hi"""`:   `public.say "hi"`,
+		"customers":             "customers",
+	}
+
+	for in, want := range tests {
+		if got := unquoteQualified(in); got != want {
+			t.Errorf("unquoteQualified(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package checker
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles/*.yaml
+var profilesFS embed.FS
+
+// Control statuses reported by a profile
+const (
+	ControlPass          = "pass"
+	ControlFail          = "fail"
+	ControlNotApplicable = "not_applicable"
+	ControlManual        = "manual"
+)
+
+// Profile maps benchmark controls onto finding codes
+type Profile struct {
+	ID       string         `yaml:"id"`
+	Title    string         `yaml:"title"`
+	Settings []SettingCheck `yaml:"settings"`
+	Controls []Control      `yaml:"controls"`
+}
+
+type Control struct {
+	ID       string         `yaml:"id"`
+	Title    string         `yaml:"title"`
+	Manual   bool           `yaml:"manual"`
+	Requires []string       `yaml:"requires"`
+	Findings []FindingMatch `yaml:"findings"`
+}
+
+// FindingMatch selects findings by code and, when Object is set, by object
+type FindingMatch struct {
+	Code   string `yaml:"code"`
+	Object string `yaml:"object"`
+}
+
+type ProfileResult struct {
+	ID       string          `json:"id"`
+	Title    string          `json:"title"`
+	Summary  map[string]int  `json:"summary"`
+	Controls []ControlResult `json:"controls"`
+}
+
+type ControlResult struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Status   string    `json:"status"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// LoadProfile returns a built-in profile by id, e.g. "cis-16"
+func LoadProfile(id string) (*Profile, error) {
+	data, err := profilesFS.ReadFile("profiles/" + id + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", id, strings.Join(Profiles(), ", "))
+	}
+
+	var p Profile
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %s: %w", id, err)
+	}
+
+	return &p, nil
+}
+
+// Profiles lists the ids of built-in profiles
+func Profiles() []string {
+	entries, _ := fs.ReadDir(profilesFS, "profiles")
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, strings.TrimSuffix(e.Name(), ".yaml"))
+	}
+	return ids
+}
+
+// withProfileSettings adds the profile's setting checks that the catalog
+// does not already define
+func withProfileSettings(checks []SettingCheck, p *Profile) []SettingCheck {
+	for _, c := range p.Settings {
+		if settingIndex(checks, c.Name) < 0 {
+			checks = append(checks, c)
+		}
+	}
+	return checks
+}
+
+// EvaluateProfile reports the status of every control in p for report
+func EvaluateProfile(p *Profile, report *Report, checks []SettingCheck) *ProfileResult {
+	result := &ProfileResult{
+		ID:       p.ID,
+		Title:    p.Title,
+		Summary:  map[string]int{ControlPass: 0, ControlFail: 0, ControlNotApplicable: 0, ControlManual: 0},
+		Controls: []ControlResult{},
+	}
+
+	for _, c := range p.Controls {
+		cr := ControlResult{ID: c.ID, Title: c.Title}
+
+		switch {
+		case c.Manual:
+			cr.Status = ControlManual
+		case !controlApplicable(c, report, checks):
+			cr.Status = ControlNotApplicable
+		default:
+			for _, f := range report.Findings {
+				if controlMatches(c, f) {
+					cr.Findings = append(cr.Findings, f)
+				}
+			}
+			cr.Status = ControlPass
+			if len(cr.Findings) > 0 {
+				cr.Status = ControlFail
+			}
+		}
+
+		result.Summary[cr.Status]++
+		result.Controls = append(result.Controls, cr)
+	}
+
+	return result
+}
+
+func controlMatches(c Control, f Finding) bool {
+	for _, m := range c.Findings {
+		if m.Code == f.Code && (m.Object == "" || m.Object == f.Object) {
+			return true
+		}
+	}
+	return false
+}
+
+// controlApplicable reports whether the data a control depends on was
+// collected and its setting checks are not disabled for this environment.
+// Any other requirement names a collector, which must have run without a
+// coverage gap: an empty result from a denied collector is not a pass.
+func controlApplicable(c Control, report *Report, checks []SettingCheck) bool {
+	for _, req := range c.Requires {
+		switch {
+		case req == "hba_rules":
+			if len(report.Instance.HBARules) == 0 {
+				return false
+			}
+		case req == "passwords":
+			if !hasPasswordInfo(report.Roles) {
+				return false
+			}
+		case strings.HasPrefix(req, "setting:"):
+			if _, ok := report.Instance.Settings[strings.TrimPrefix(req, "setting:")]; !ok {
+				return false
+			}
+		default:
+			if hasCoverageGap(report.CoverageGaps, req) {
+				return false
+			}
+		}
+	}
+
+	for _, m := range c.Findings {
+		if m.Code != "SETTING_MISMATCH" {
+			continue
+		}
+		i := settingIndex(checks, m.Object)
+		if i < 0 || checks[i].Disabled {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasCoverageGap reports whether collector, or a whole database it runs in,
+// could not be read
+func hasCoverageGap(gaps []CoverageGap, collector string) bool {
+	for _, g := range gaps {
+		if g.Collector == collector || g.Collector == "database" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPasswordInfo(roles []RoleInfo) bool {
+	for _, r := range roles {
+		if r.Password != nil {
+			return true
+		}
+	}
+	return false
+}
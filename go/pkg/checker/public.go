@@ -0,0 +1,160 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PublicGrant is a privilege held by the PUBLIC pseudo-role, i.e. by everyone
+type PublicGrant struct {
+	ObjectType string `json:"object_type"`
+	Object     string `json:"object"`
+	Privilege  string `json:"privilege"`
+}
+
+func getPublicGrants(ctx context.Context, conn Querier) ([]PublicGrant, error) {
+	// acldefault() fills in the implicit ACL for objects that were never
+	// GRANTed or REVOKEd, e.g. EXECUTE on functions and TEMP on databases.
+	query := `
+		SELECT 'schema', quote_ident(n.nspname), a.privilege_type
+		FROM pg_namespace n,
+			aclexplode(coalesce(n.nspacl, acldefault('n', n.nspowner))) a
+		WHERE a.grantee = 0
+		  AND n.nspname NOT LIKE 'pg_%'
+		  AND n.nspname <> 'information_schema'
+
+		UNION ALL
+
+		SELECT
+			CASE WHEN c.relkind = 'S' THEN 'sequence' ELSE 'table' END,
+			quote_ident(n.nspname) || '.' || quote_ident(c.relname),
+			a.privilege_type
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace,
+			aclexplode(coalesce(c.relacl, acldefault(CASE WHEN c.relkind = 'S' THEN 's' ELSE 'r' END::"char", c.relowner))) a
+		WHERE a.grantee = 0
+		  AND c.relkind IN ('r', 'p', 'v', 'm', 'f', 'S')
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+
+		UNION ALL
+
+		SELECT
+			'function',
+			quote_ident(n.nspname) || '.' || quote_ident(p.proname) || '(' || pg_get_function_identity_arguments(p.oid) || ')',
+			a.privilege_type
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace,
+			aclexplode(coalesce(p.proacl, acldefault('f', p.proowner))) a
+		WHERE a.grantee = 0
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND NOT EXISTS (
+			SELECT 1 FROM pg_depend d
+			WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e'
+		  )
+
+		UNION ALL
+
+		SELECT 'database', quote_ident(d.datname), a.privilege_type
+		FROM pg_database d,
+			aclexplode(coalesce(d.datacl, acldefault('d', d.datdba))) a
+		WHERE a.grantee = 0
+		  AND NOT d.datistemplate
+
+		ORDER BY 1, 2, 3
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []PublicGrant
+	for rows.Next() {
+		var g PublicGrant
+		if err := rows.Scan(&g.ObjectType, &g.Object, &g.Privilege); err != nil {
+			return nil, err
+		}
+		grants = append(grants, g)
+	}
+
+	return grants, rows.Err()
+}
+
+func publicGrantFindings(grants []PublicGrant, opts Options) []Finding {
+	var findings []Finding
+
+	sensitive := make(map[string]bool)
+	for _, db := range opts.SensitiveDatabases {
+		sensitive[db] = true
+	}
+
+	for _, g := range grants {
+		f := Finding{
+			ObjectType: g.ObjectType,
+			Object:     g.Object,
+		}
+
+		switch {
+		case g.ObjectType == "schema" && g.Privilege == "CREATE":
+			f.Severity = "high"
+			f.Code = "PUBLIC_SCHEMA_CREATE"
+			f.Message = fmt.Sprintf("PUBLIC can create objects in schema %s", g.Object)
+			f.Remediation = fix(fmt.Sprintf("REVOKE CREATE ON SCHEMA %s FROM PUBLIC;", g.Object))
+		case g.ObjectType == "table":
+			f.Severity = "high"
+			f.Code = "PUBLIC_TABLE_PRIVILEGE"
+			f.Message = fmt.Sprintf("PUBLIC has %s on table %s", g.Privilege, g.Object)
+			f.Remediation = fix(fmt.Sprintf("REVOKE %s ON TABLE %s FROM PUBLIC;", g.Privilege, g.Object))
+		case g.ObjectType == "sequence":
+			f.Severity = "warning"
+			f.Code = "PUBLIC_SEQUENCE_PRIVILEGE"
+			f.Message = fmt.Sprintf("PUBLIC has %s on sequence %s", g.Privilege, g.Object)
+			f.Remediation = fix(fmt.Sprintf("REVOKE %s ON SEQUENCE %s FROM PUBLIC;", g.Privilege, g.Object))
+		case g.ObjectType == "function" && g.Privilege == "EXECUTE":
+			f.Severity = "info"
+			f.Code = "PUBLIC_FUNCTION_EXECUTE"
+			f.Message = fmt.Sprintf("PUBLIC can execute function %s", g.Object)
+			f.Remediation = fix(fmt.Sprintf("REVOKE EXECUTE ON FUNCTION %s FROM PUBLIC;", g.Object))
+		case g.ObjectType == "database" && g.Privilege == "TEMPORARY":
+			f.Severity = "warning"
+			f.Code = "PUBLIC_DATABASE_TEMP"
+			f.Message = fmt.Sprintf("PUBLIC can create temporary objects in database %s", g.Object)
+			f.Remediation = fix(fmt.Sprintf("REVOKE TEMPORARY ON DATABASE %s FROM PUBLIC;", g.Object))
+		case g.ObjectType == "database" && g.Privilege == "CONNECT" && sensitive[unquoteIdent(g.Object)]:
+			f.Severity = "high"
+			f.Code = "PUBLIC_DATABASE_CONNECT"
+			f.Message = fmt.Sprintf("PUBLIC can connect to sensitive database %s", g.Object)
+			f.Remediation = fix(fmt.Sprintf("REVOKE CONNECT ON DATABASE %s FROM PUBLIC;", g.Object))
+		default:
+			continue
+		}
+
+		findings = append(findings, f)
+	}
+
+	return findings
+}
+
+// unquoteIdent reverses quote_ident() for a single identifier
+func unquoteIdent(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+	}
+	return s
+}
+
+// unquoteQualified reverses quote_ident() for a schema-qualified name
+func unquoteQualified(s string) string {
+	quoted := false
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '.' && !quoted:
+			return unquoteIdent(s[:i]) + "." + unquoteIdent(s[i+1:])
+		}
+	}
+	return unquoteIdent(s)
+}
@@ -0,0 +1,56 @@
+package checker
+
+import (
+	_ "embed"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed remediations.yaml
+var defaultRemediationsYAML []byte
+
+// Remediation tells how to fix a finding. SQL is specific to the finding's
+// object and empty when the fix is outside the database (pg_hba.conf,
+// package upgrades) or needs a decision (a new password, a policy).
+type Remediation struct {
+	Explanation    string `yaml:"explanation" json:"explanation"`
+	SQL            string `yaml:"-" json:"sql,omitempty"`
+	Risk           string `yaml:"risk" json:"risk,omitempty"`
+	SafeToAutomate bool   `yaml:"safe_to_automate" json:"safe_to_automate"`
+}
+
+var remediations = loadRemediations()
+
+func loadRemediations() map[string]Remediation {
+	var catalog struct {
+		Remediations map[string]Remediation `yaml:"remediations"`
+	}
+	if err := yaml.Unmarshal(defaultRemediationsYAML, &catalog); err != nil {
+		panic(fmt.Sprintf("invalid built-in remediation catalog: %v", err))
+	}
+	return catalog.Remediations
+}
+
+// fix is the remediation of a finding before the catalog guidance is added
+func fix(sql string) *Remediation {
+	return &Remediation{SQL: sql}
+}
+
+// AttachRemediations fills the explanation, risk and safety of each finding
+// from the catalog, keeping the SQL built for the finding
+func AttachRemediations(findings []Finding) {
+	for i := range findings {
+		f := &findings[i]
+		guide, ok := remediations[f.Code]
+		if !ok {
+			continue
+		}
+		if f.Remediation == nil {
+			f.Remediation = &Remediation{}
+		}
+		f.Remediation.Explanation = guide.Explanation
+		f.Remediation.Risk = guide.Risk
+		f.Remediation.SafeToAutomate = guide.SafeToAutomate
+	}
+}
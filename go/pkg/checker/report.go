@@ -0,0 +1,22 @@
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadReport reads a JSON report previously produced by analyze
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report file: %w", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report JSON: %w", err)
+	}
+
+	return &report, nil
+}
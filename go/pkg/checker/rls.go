@@ -0,0 +1,156 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type PolicyInfo struct {
+	Name       string   `json:"name"`
+	Command    string   `json:"command"`
+	Permissive bool     `json:"permissive"`
+	Roles      []string `json:"roles"`
+	Using      *string  `json:"using,omitempty"`
+	WithCheck  *string  `json:"with_check,omitempty"`
+}
+
+// AppliesToPublic reports whether the policy has no role restriction
+func (p PolicyInfo) AppliesToPublic() bool {
+	return contains(p.Roles, "public")
+}
+
+// getPolicies attaches pg_policy entries to the matching tables
+func getPolicies(ctx context.Context, conn Querier, tables []TableInfo) error {
+	query := `
+		SELECT
+			n.nspname,
+			c.relname,
+			p.polname,
+			CASE p.polcmd
+				WHEN 'r' THEN 'SELECT'
+				WHEN 'a' THEN 'INSERT'
+				WHEN 'w' THEN 'UPDATE'
+				WHEN 'd' THEN 'DELETE'
+				ELSE 'ALL'
+			END,
+			p.polpermissive,
+			ARRAY(
+				SELECT CASE WHEN r.oid = 0 THEN 'public' ELSE pg_get_userbyid(r.oid) END
+				FROM unnest(p.polroles) AS r(oid)
+				ORDER BY 1
+			),
+			pg_get_expr(p.polqual, p.polrelid),
+			pg_get_expr(p.polwithcheck, p.polrelid)
+		FROM pg_policy p
+		JOIN pg_class c ON c.oid = p.polrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		ORDER BY n.nspname, c.relname, p.polname
+	`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[string]int)
+	for i, t := range tables {
+		index[t.Schema+"."+t.Name] = i
+	}
+
+	for rows.Next() {
+		var schema, table string
+		var p PolicyInfo
+		if err := rows.Scan(&schema, &table, &p.Name, &p.Command, &p.Permissive,
+			&p.Roles, &p.Using, &p.WithCheck); err != nil {
+			return err
+		}
+		if i, ok := index[schema+"."+table]; ok {
+			tables[i].Policies = append(tables[i].Policies, p)
+		}
+	}
+
+	return rows.Err()
+}
+
+func rlsFindings(tables []TableInfo) []Finding {
+	var findings []Finding
+
+	for _, t := range tables {
+		name := t.Schema + "." + t.Name
+		qualified := quoteQualified(t.Schema, t.Name)
+
+		add := func(severity, code, message, remediation string) {
+			findings = append(findings, Finding{
+				Severity:    severity,
+				Code:        code,
+				Message:     message,
+				ObjectType:  "table",
+				Object:      name,
+				Remediation: fix(remediation),
+			})
+		}
+
+		if !t.RLSEnabled {
+			continue
+		}
+
+		if len(t.Policies) == 0 {
+			add("warning", "RLS_NO_POLICIES",
+				fmt.Sprintf("Table %s has RLS enabled but no policies, so every non-owner query returns no rows", name),
+				"")
+		}
+
+		if !t.RLSForced && t.OwnerLogin {
+			add("warning", "RLS_NOT_FORCED",
+				fmt.Sprintf("Table %s does not force RLS and its owner %s is a login role that bypasses the policies", name, t.Owner),
+				fmt.Sprintf("ALTER TABLE %s FORCE ROW LEVEL SECURITY;", qualified))
+		}
+
+		// Restrictive policies are AND-ed with the permissive ones, so only
+		// another permissive policy can be widened by a PUBLIC one
+		permissive := 0
+		for _, p := range t.Policies {
+			if p.Permissive {
+				permissive++
+			}
+		}
+
+		for _, p := range t.Policies {
+			if p.Using != nil && isTrueExpr(*p.Using) {
+				add("high", "RLS_POLICY_ALWAYS_TRUE",
+					fmt.Sprintf("Policy %s on %s uses USING (true) for %s and filters nothing", p.Name, name, strings.Join(p.Roles, ", ")),
+					"")
+			}
+
+			if p.Permissive && p.AppliesToPublic() && permissive > 1 {
+				add("warning", "RLS_PERMISSIVE_PUBLIC",
+					fmt.Sprintf("Permissive policy %s on %s applies to PUBLIC; permissive policies are OR-ed, so it widens the rows every role can see beyond the other permissive policies", p.Name, name),
+					"")
+			}
+		}
+	}
+
+	return findings
+}
+
+func isTrueExpr(expr string) bool {
+	e := strings.ToLower(strings.TrimSpace(expr))
+	for strings.HasPrefix(e, "(") && strings.HasSuffix(e, ")") {
+		e = strings.TrimSpace(e[1 : len(e)-1])
+	}
+	return e == "true"
+}
+
+func quoteQualified(schema, name string) string {
+	return quoteIdentAlways(schema) + "." + quoteIdentAlways(name)
+}
+
+func quoteIdentAlways(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func quoteLiteral(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
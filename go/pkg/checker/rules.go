@@ -0,0 +1,330 @@
+package checker
+
+import "sort"
+
+// Rule describes a finding code independently of any particular report
+type Rule struct {
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+}
+
+var rules = map[string]Rule{
+	"NO_RLS": {
+		Code:        "NO_RLS",
+		Title:       "Table without Row Level Security",
+		Description: "The table does not have Row Level Security enabled, so every role with table privileges sees all rows.",
+		Severity:    "warning",
+	},
+	"SSL_DISABLED": {
+		Code:        "SSL_DISABLED",
+		Title:       "SSL disabled",
+		Description: "The server does not accept TLS connections, so credentials and data travel in clear text.",
+		Severity:    "high",
+	},
+	"SUPERUSER_LOGIN": {
+		Code:        "SUPERUSER_LOGIN",
+		Title:       "Superuser with login",
+		Description: "A superuser role can log in directly. Superusers bypass every permission check and should not be used by applications or people day to day.",
+		Severity:    "critical",
+	},
+	"BYPASS_RLS": {
+		Code:        "BYPASS_RLS",
+		Title:       "Role bypasses RLS",
+		Description: "The role has the BYPASSRLS attribute and ignores every Row Level Security policy.",
+		Severity:    "warning",
+	},
+	"HBA_TRUST": {
+		Code:        "HBA_TRUST",
+		Title:       "pg_hba.conf uses trust authentication",
+		Description: "Clients matching the rule connect without any password. Remote trust rules let anyone who can reach the port log in as any matching role.",
+		Severity:    "critical",
+	},
+	"HBA_PASSWORD": {
+		Code:        "HBA_PASSWORD",
+		Title:       "pg_hba.conf uses clear-text password authentication",
+		Description: "The password method sends the password in clear text over the connection. Use scram-sha-256 instead.",
+		Severity:    "high",
+	},
+	"HBA_OPEN_WORLD": {
+		Code:        "HBA_OPEN_WORLD",
+		Title:       "pg_hba.conf allows all databases and users from any address",
+		Description: "A host rule for all databases and all users accepts connections from 0.0.0.0/0 or ::/0. Restrict the address range to known networks.",
+		Severity:    "high",
+	},
+	"HBA_NO_SSL": {
+		Code:        "HBA_NO_SSL",
+		Title:       "Remote pg_hba.conf rule does not require SSL",
+		Description: "The rule matches non-loopback clients with host or hostnossl, so remote sessions may be unencrypted. Use hostssl for remote ranges.",
+		Severity:    "warning",
+	},
+	"HBA_PARSE_ERROR": {
+		Code:        "HBA_PARSE_ERROR",
+		Title:       "pg_hba.conf rule cannot be parsed",
+		Description: "The server reports an error for this line. After the next reload the whole file may be rejected and authentication may not behave as intended.",
+		Severity:    "warning",
+	},
+	"HBA_REPLICATION_WIDE": {
+		Code:        "HBA_REPLICATION_WIDE",
+		Title:       "Replication connections allowed from a wide network",
+		Description: "Replication connections can stream the whole cluster. Limit replication entries to the addresses of known standbys.",
+		Severity:    "high",
+	},
+	"LOGIN_NO_PASSWORD": {
+		Code:        "LOGIN_NO_PASSWORD",
+		Title:       "Login role without password",
+		Description: "The role can log in but has no password, so it is protected only by pg_hba.conf methods such as trust, peer or cert.",
+		Severity:    "warning",
+	},
+	"PASSWORD_MD5": {
+		Code:        "PASSWORD_MD5",
+		Title:       "MD5 password while SCRAM is the default",
+		Description: "The role's password is stored as an MD5 hash although password_encryption is scram-sha-256. Reset the password so it is stored as a SCRAM verifier.",
+		Severity:    "warning",
+	},
+	"PASSWORD_NO_EXPIRY": {
+		Code:        "PASSWORD_NO_EXPIRY",
+		Title:       "Password without expiry",
+		Description: "The login role has a password but no VALID UNTIL date, so a leaked password stays valid indefinitely.",
+		Severity:    "info",
+	},
+	"PASSWORD_EXPIRED_IN_USE": {
+		Code:        "PASSWORD_EXPIRED_IN_USE",
+		Title:       "Expired password still in use",
+		Description: "The role's password has expired but the role still has active sessions, which usually means it authenticates by a method that ignores VALID UNTIL or the sessions predate the expiry.",
+		Severity:    "warning",
+	},
+	"PASSWORD_EQUALS_NAME": {
+		Code:        "PASSWORD_EQUALS_NAME",
+		Title:       "Password equals role name",
+		Description: "The role's password is its own name. This is checked locally against the stored SCRAM or MD5 verifier; the password is never sent or logged.",
+		Severity:    "critical",
+	},
+	"PUBLIC_SCHEMA_CREATE": {
+		Code:        "PUBLIC_SCHEMA_CREATE",
+		Title:       "PUBLIC can create objects in a schema",
+		Description: "Every role can create tables and functions in the schema. In schema public this enables search_path hijacking of other users' queries (CVE-2018-1058).",
+		Severity:    "high",
+	},
+	"PUBLIC_TABLE_PRIVILEGE": {
+		Code:        "PUBLIC_TABLE_PRIVILEGE",
+		Title:       "Table privilege granted to PUBLIC",
+		Description: "A privilege on the table or view is granted to PUBLIC, so every current and future role holds it.",
+		Severity:    "high",
+	},
+	"PUBLIC_SEQUENCE_PRIVILEGE": {
+		Code:        "PUBLIC_SEQUENCE_PRIVILEGE",
+		Title:       "Sequence privilege granted to PUBLIC",
+		Description: "A privilege on the sequence is granted to PUBLIC, so every role can read or advance it.",
+		Severity:    "warning",
+	},
+	"PUBLIC_FUNCTION_EXECUTE": {
+		Code:        "PUBLIC_FUNCTION_EXECUTE",
+		Title:       "Function executable by PUBLIC",
+		Description: "PostgreSQL grants EXECUTE to PUBLIC on new functions by default. Revoke it for functions that should only be called by specific roles.",
+		Severity:    "info",
+	},
+	"PUBLIC_DATABASE_TEMP": {
+		Code:        "PUBLIC_DATABASE_TEMP",
+		Title:       "PUBLIC can create temporary objects",
+		Description: "Every role that can connect may create temporary tables and functions in the database, which can be used to shadow objects through search_path.",
+		Severity:    "warning",
+	},
+	"PUBLIC_DATABASE_CONNECT": {
+		Code:        "PUBLIC_DATABASE_CONNECT",
+		Title:       "PUBLIC can connect to a sensitive database",
+		Description: "Every role in the cluster may connect to a database marked as sensitive. Revoke CONNECT from PUBLIC and grant it to the roles that need it.",
+		Severity:    "high",
+	},
+	"SECDEF_NO_SEARCH_PATH": {
+		Code:        "SECDEF_NO_SEARCH_PATH",
+		Title:       "SECURITY DEFINER function without pinned search_path",
+		Description: "The function runs with its owner's privileges but resolves names through the caller's search_path, so a caller can shadow tables, operators or functions it uses.",
+		Severity:    "high",
+	},
+	"SECDEF_SUPERUSER_OWNER": {
+		Code:        "SECDEF_SUPERUSER_OWNER",
+		Title:       "SECURITY DEFINER function owned by a superuser",
+		Description: "Any flaw in the function gives its callers superuser privileges. Transfer ownership to a dedicated role with only the privileges the function needs.",
+		Severity:    "high",
+	},
+	"SECDEF_PUBLIC_EXECUTE": {
+		Code:        "SECDEF_PUBLIC_EXECUTE",
+		Title:       "SECURITY DEFINER function executable by PUBLIC",
+		Description: "Every role can run the function with its owner's privileges. Revoke EXECUTE from PUBLIC and grant it to the roles that need it.",
+		Severity:    "warning",
+	},
+	"SECDEF_UNTRUSTED_LANGUAGE": {
+		Code:        "SECDEF_UNTRUSTED_LANGUAGE",
+		Title:       "SECURITY DEFINER function in an untrusted language",
+		Description: "Untrusted languages such as plpython3u or plperlu can access the server's file system and processes; combined with SECURITY DEFINER they expose that access to callers.",
+		Severity:    "high",
+	},
+	"RLS_NO_POLICIES": {
+		Code:        "RLS_NO_POLICIES",
+		Title:       "RLS enabled without policies",
+		Description: "Row Level Security is enabled but no policy exists, so the default-deny rule hides every row from roles other than the owner and BYPASSRLS roles.",
+		Severity:    "warning",
+	},
+	"RLS_NOT_FORCED": {
+		Code:        "RLS_NOT_FORCED",
+		Title:       "RLS not forced for a login owner",
+		Description: "Table owners bypass RLS unless FORCE ROW LEVEL SECURITY is set. The owner is a login role, so sessions using it see all rows.",
+		Severity:    "warning",
+	},
+	"RLS_POLICY_ALWAYS_TRUE": {
+		Code:        "RLS_POLICY_ALWAYS_TRUE",
+		Title:       "RLS policy with USING (true)",
+		Description: "The policy's USING expression is constant true, so it grants the listed roles access to every row.",
+		Severity:    "high",
+	},
+	"RLS_PERMISSIVE_PUBLIC": {
+		Code:        "RLS_PERMISSIVE_PUBLIC",
+		Title:       "Permissive RLS policy for PUBLIC",
+		Description: "Permissive policies are combined with OR. A permissive policy for PUBLIC on a table with other permissive policies widens access for every role and can nullify the intent of narrower ones; restrictive policies still apply on top.",
+		Severity:    "warning",
+	},
+	"ROLE_REACHES_PRIVILEGED": {
+		Code:        "ROLE_REACHES_PRIVILEGED",
+		Title:       "Login role reaches a superuser-equivalent role",
+		Description: "Through a chain of memberships with INHERIT or SET the login role can act as a superuser or as pg_execute_server_program, pg_write_server_files or pg_read_server_files, which allow running programs or accessing files on the server.",
+		Severity:    "critical",
+	},
+	"MASKED_COLUMN_EXPOSED": {
+		Code:        "MASKED_COLUMN_EXPOSED",
+		Title:       "Masked column readable directly",
+		Description: "policy.yaml exposes the column only through a masking view, but the role can read the raw column through a table-level or column-level SELECT grant.",
+		Severity:    "high",
+	},
+	"GRANTABLE_BY_NON_OWNER": {
+		Code:        "GRANTABLE_BY_NON_OWNER",
+		Title:       "Privilege WITH GRANT OPTION held by a non-owner",
+		Description: "A role other than the owner can pass the privilege on to any other role, so access to the object is no longer controlled by its owner alone.",
+		Severity:    "warning",
+	},
+	"DEFAULT_ACL_PUBLIC": {
+		Code:        "DEFAULT_ACL_PUBLIC",
+		Title:       "Default privileges grant to PUBLIC",
+		Description: "ALTER DEFAULT PRIVILEGES makes every new object of this type accessible to all roles as soon as it is created.",
+		Severity:    "high",
+	},
+	"DEFAULT_ACL_LOGIN_ROLE": {
+		Code:        "DEFAULT_ACL_LOGIN_ROLE",
+		Title:       "Default privileges grant to a login role",
+		Description: "New objects are granted directly to a login role. Grant default privileges to group roles instead so access is managed through membership.",
+		Severity:    "warning",
+	},
+	"EXTENSION_DANGEROUS": {
+		Code:        "EXTENSION_DANGEROUS",
+		Title:       "Dangerous extension installed",
+		Description: "Extensions such as dblink, adminpack and file_fdw let database users reach other servers or the server's file system. Remove them unless they are required.",
+		Severity:    "high",
+	},
+	"EXTENSION_IN_PUBLIC": {
+		Code:        "EXTENSION_IN_PUBLIC",
+		Title:       "Extension installed in schema public",
+		Description: "Extension objects in public can be shadowed by objects created by other roles. Install extensions into a dedicated schema.",
+		Severity:    "warning",
+	},
+	"EXTENSION_OUTDATED": {
+		Code:        "EXTENSION_OUTDATED",
+		Title:       "Extension version outdated",
+		Description: "A newer version of the extension is available on the server (pg_available_extension_versions) but has not been applied with ALTER EXTENSION ... UPDATE.",
+		Severity:    "warning",
+	},
+	"UNTRUSTED_LANGUAGE": {
+		Code:        "UNTRUSTED_LANGUAGE",
+		Title:       "Untrusted procedural language installed",
+		Description: "Untrusted languages such as plpython3u or plperlu run code with the privileges of the database server process.",
+		Severity:    "warning",
+	},
+	"SETTING_MISMATCH": {
+		Code:        "SETTING_MISMATCH",
+		Title:       "Server setting differs from expectation",
+		Description: "A server setting does not match the settings catalog (built-in or overridden with --settings). The message names the setting, its value and the expected value.",
+		Severity:    "warning",
+	},
+	"VERSION_EOL": {
+		Code:        "VERSION_EOL",
+		Title:       "PostgreSQL major version is end-of-life",
+		Description: "The major version no longer receives bug or security fixes. Upgrade to a supported major version.",
+		Severity:    "critical",
+	},
+	"VERSION_MISSING_SECURITY_FIXES": {
+		Code:        "VERSION_MISSING_SECURITY_FIXES",
+		Title:       "Security fixes not applied",
+		Description: "Later minor releases of this major version fix known vulnerabilities (listed by CVE). Minor upgrades do not require dump/restore.",
+		Severity:    "high",
+	},
+	"VERSION_OUTDATED_MINOR": {
+		Code:        "VERSION_OUTDATED_MINOR",
+		Title:       "Minor release outdated",
+		Description: "A newer minor release of this major version is available.",
+		Severity:    "warning",
+	},
+	"PII_COLUMN_UNMASKED": {
+		Code:        "PII_COLUMN_UNMASKED",
+		Title:       "PII column readable without a mask",
+		Description: "The column looks like personal data (by name, type, comment or sampled values) and roles can read it directly, but policy.yaml defines no mask for it.",
+		Severity:    "high",
+	},
+	"ROLE_NEVER_CONNECTS": {
+		Code:        "ROLE_NEVER_CONNECTS",
+		Title:       "Login role never connects",
+		Description: "The login role has no recorded connections in pg_stat_activity, the supplied connection logs or earlier reports. Unused credentials are an easy target; remove LOGIN or drop the role.",
+		Severity:    "warning",
+	},
+	"ROLE_UNUSED": {
+		Code:        "ROLE_UNUSED",
+		Title:       "Unused role",
+		Description: "The role has no privileges, no members, belongs to no role and owns no objects.",
+		Severity:    "low",
+	},
+	"ROLE_GRANTS_UNUSED": {
+		Code:        "ROLE_GRANTS_UNUSED",
+		Title:       "Grants on untouched tables",
+		Description: "The role has privileges on tables that show no reads or writes in pg_stat_user_tables since the statistics were last reset. The statistics are per table, not per role: grants on tables any role uses are not reported, even if this role never uses them.",
+		Severity:    "low",
+	},
+	"SESSION_NO_TLS": {
+		Code:        "SESSION_NO_TLS",
+		Title:       "Session without encryption",
+		Description: "A client connected over the network without TLS or GSSAPI encryption (pg_stat_ssl, pg_stat_gssapi). Unix-socket and loopback sessions are not reported.",
+		Severity:    "warning",
+	},
+	"SESSION_SUPERUSER": {
+		Code:        "SESSION_SUPERUSER",
+		Title:       "Superuser session",
+		Description: "A client is connected as a superuser. Day-to-day work should use roles with only the privileges it needs.",
+		Severity:    "warning",
+	},
+	"SESSION_IDLE_IN_TRANSACTION": {
+		Code:        "SESSION_IDLE_IN_TRANSACTION",
+		Title:       "Long idle-in-transaction session holding locks",
+		Description: "The session has been idle inside a transaction for longer than the threshold while holding locks, blocking other sessions and vacuum.",
+		Severity:    "warning",
+	},
+	"SESSION_UNEXPECTED_CLIENT": {
+		Code:        "SESSION_UNEXPECTED_CLIENT",
+		Title:       "Connection from unexpected address",
+		Description: "A client is connected from an address outside the allowlist passed with --client-allowlist.",
+		Severity:    "high",
+	},
+}
+
+// LookupRule returns the catalog entry for a finding code
+func LookupRule(code string) (Rule, bool) {
+	r, ok := rules[code]
+	return r, ok
+}
+
+// Rules returns all known rules ordered by code
+func Rules() []Rule {
+	list := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
@@ -0,0 +1,208 @@
+package checker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// defaultIdleInTransaction is how long a session may stay idle in a
+// transaction while holding locks before it is reported
+const defaultIdleInTransaction = 5 * time.Minute
+
+// ApplicationName is sent by every connection this process opens, so the
+// session checks can tell the tool's own pool connections from clients. The
+// random suffix keeps other clients from hiding behind the name.
+var ApplicationName = "pg-sec-lab-" + randomSuffix()
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SetApplicationName marks a connection config as one of the tool's own,
+// overriding any application_name from the DSN
+func SetApplicationName(config *pgx.ConnConfig) {
+	config.RuntimeParams["application_name"] = ApplicationName
+}
+
+// SessionInfo is one client backend from pg_stat_activity. Query text is not
+// collected.
+type SessionInfo struct {
+	PID             int        `json:"pid"`
+	BackendStart    *time.Time `json:"backend_start,omitempty"`
+	User            string     `json:"user"`
+	Database        string     `json:"database"`
+	ClientAddr      string     `json:"client_addr,omitempty"`
+	ApplicationName string     `json:"application_name,omitempty"`
+	State           string     `json:"state"`
+	StateSeconds    float64    `json:"state_seconds"`
+	SSL             bool       `json:"ssl"`
+	SSLVersion      string     `json:"ssl_version,omitempty"`
+	GSSAuth         bool       `json:"gss_authenticated"`
+	GSSEncrypted    bool       `json:"gss_encrypted"`
+	Superuser       bool       `json:"superuser"`
+	LocksHeld       int        `json:"locks_held"`
+}
+
+func getSessions(ctx context.Context, conn Querier) ([]SessionInfo, error) {
+	query := `
+		SELECT
+			a.pid,
+			a.backend_start,
+			coalesce(a.usename, ''),
+			coalesce(a.datname, ''),
+			coalesce(host(a.client_addr), ''),
+			coalesce(a.application_name, ''),
+			coalesce(a.state, ''),
+			coalesce(extract(epoch FROM now() - a.state_change), 0)::float8,
+			coalesce(s.ssl, false),
+			coalesce(s.version, ''),
+			coalesce(g.gss_authenticated, false),
+			coalesce(g.encrypted, false),
+			coalesce(r.rolsuper, false),
+			(SELECT count(*) FROM pg_locks l
+			 WHERE l.pid = a.pid AND l.granted
+			   AND l.locktype NOT IN ('virtualxid', 'transactionid'))
+		FROM pg_stat_activity a
+		LEFT JOIN pg_stat_ssl s ON s.pid = a.pid
+		LEFT JOIN pg_stat_gssapi g ON g.pid = a.pid
+		LEFT JOIN pg_roles r ON r.oid = a.usesysid
+		WHERE a.backend_type = 'client backend'
+		  AND a.pid <> pg_backend_pid()
+		  AND NOT (a.application_name = $1 AND a.usename = current_user)
+		ORDER BY a.pid
+	`
+
+	rows, err := conn.Query(ctx, query, ApplicationName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []SessionInfo
+	for rows.Next() {
+		var s SessionInfo
+		if err := rows.Scan(&s.PID, &s.BackendStart, &s.User, &s.Database, &s.ClientAddr, &s.ApplicationName,
+			&s.State, &s.StateSeconds, &s.SSL, &s.SSLVersion, &s.GSSAuth, &s.GSSEncrypted,
+			&s.Superuser, &s.LocksHeld); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// ParseClientAllowlist parses addresses and CIDR ranges; a plain address
+// allows that host only
+func ParseClientAllowlist(entries []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if !strings.Contains(e, "/") {
+			ip := net.ParseIP(e)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid client address %q", e)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client network %q: %w", e, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func sessionFindings(sessions []SessionInfo, allowlist []*net.IPNet, idleThreshold time.Duration) []Finding {
+	var findings []Finding
+
+	if idleThreshold <= 0 {
+		idleThreshold = defaultIdleInTransaction
+	}
+
+	for _, s := range sessions {
+		object := fmt.Sprintf("pid %d", s.PID)
+		who := fmt.Sprintf("Session %d (%s@%s", s.PID, s.User, s.Database)
+		if s.ClientAddr != "" {
+			who += " from " + s.ClientAddr
+		}
+		who += ")"
+
+		add := func(severity, code, message, remediation string) {
+			findings = append(findings, Finding{
+				Severity:    severity,
+				Code:        code,
+				Message:     message,
+				ObjectType:  "session",
+				Object:      object,
+				Remediation: fix(remediation),
+			})
+		}
+
+		// Unix-socket and loopback connections do not cross the network
+		ip := net.ParseIP(s.ClientAddr)
+		remote := ip != nil && !ip.IsLoopback()
+
+		if remote && !s.SSL && !s.GSSEncrypted {
+			add("warning", "SESSION_NO_TLS",
+				who+" is not encrypted with TLS or GSSAPI", "")
+		}
+
+		if s.Superuser {
+			add("warning", "SESSION_SUPERUSER",
+				who+" is connected as a superuser", "")
+		}
+
+		idle := time.Duration(s.StateSeconds * float64(time.Second))
+		if strings.HasPrefix(s.State, "idle in transaction") && s.LocksHeld > 0 && idle >= idleThreshold {
+			add("warning", "SESSION_IDLE_IN_TRANSACTION",
+				fmt.Sprintf("%s has been %s for %s holding %d lock(s)", who, s.State, idle.Truncate(time.Second), s.LocksHeld),
+				terminateSQL(s))
+		}
+
+		if remote && len(allowlist) > 0 && !allowedClient(ip, allowlist) {
+			add("high", "SESSION_UNEXPECTED_CLIENT",
+				who+" comes from an address outside the client allowlist",
+				terminateSQL(s))
+		}
+	}
+
+	return findings
+}
+
+// terminateSQL ends the session only if it is still the same backend: the
+// script may run long after analyze, when the PID belongs to another client
+func terminateSQL(s SessionInfo) string {
+	if s.BackendStart == nil {
+		return ""
+	}
+	return fmt.Sprintf("SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE pid = %d AND backend_start = %s AND usename = %s;",
+		s.PID, quoteLiteral(s.BackendStart.UTC().Format("2006-01-02 15:04:05.999999Z07:00")), quoteLiteral(s.User))
+}
+
+func allowedClient(ip net.IP, allowlist []*net.IPNet) bool {
+	for _, n := range allowlist {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
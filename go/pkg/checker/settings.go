@@ -0,0 +1,240 @@
+package checker
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed settings.yaml
+var defaultSettingsYAML []byte
+
+// SettingCheck is one expectation about a server setting
+type SettingCheck struct {
+	Name      string `yaml:"name" json:"name"`
+	Op        string `yaml:"op" json:"op"`
+	Value     string `yaml:"value" json:"value"`
+	Severity  string `yaml:"severity" json:"severity"`
+	Rationale string `yaml:"rationale" json:"rationale"`
+	Disabled  bool   `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+type settingCatalog struct {
+	Checks []SettingCheck `yaml:"checks"`
+}
+
+// DefaultSettingChecks returns the built-in settings catalog
+func DefaultSettingChecks() []SettingCheck {
+	var catalog settingCatalog
+	if err := yaml.Unmarshal(defaultSettingsYAML, &catalog); err != nil {
+		panic(fmt.Sprintf("invalid built-in settings catalog: %v", err))
+	}
+	return catalog.Checks
+}
+
+// LoadSettingChecks reads an override file in the catalog format and applies
+// it on top of the built-in checks. Entries with a known name replace the
+// non-empty fields of the built-in check (disabled: true removes it), other
+// entries are added.
+func LoadSettingChecks(path string) ([]SettingCheck, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var overrides settingCatalog
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse settings YAML: %w", err)
+	}
+
+	checks := DefaultSettingChecks()
+	for _, o := range overrides.Checks {
+		if o.Name == "" {
+			return nil, fmt.Errorf("settings check without name")
+		}
+
+		i := settingIndex(checks, o.Name)
+		if i < 0 {
+			if o.Op == "" || o.Severity == "" {
+				return nil, fmt.Errorf("settings check %s: op and severity are required", o.Name)
+			}
+			checks = append(checks, o)
+			continue
+		}
+
+		c := &checks[i]
+		if o.Op != "" {
+			c.Op = o.Op
+		}
+		if o.Value != "" {
+			c.Value = o.Value
+		}
+		if o.Severity != "" {
+			c.Severity = o.Severity
+		}
+		if o.Rationale != "" {
+			c.Rationale = o.Rationale
+		}
+		c.Disabled = o.Disabled
+	}
+
+	for _, c := range checks {
+		if _, ok := settingOps[c.Op]; !ok {
+			return nil, fmt.Errorf("settings check %s: unknown op %q", c.Name, c.Op)
+		}
+	}
+
+	return checks, nil
+}
+
+func settingIndex(checks []SettingCheck, name string) int {
+	for i, c := range checks {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// settingOps describe each comparison for finding messages
+var settingOps = map[string]string{
+	"eq":       "%s",
+	"ne":       "anything but %s",
+	"in":       "one of %s",
+	"not_in":   "none of %s",
+	"min":      "at least %s",
+	"max":      "at most %s",
+	"includes": "a list including %s",
+	"has":      "a value containing %s",
+}
+
+// Expectation describes the expected value in words
+func (c SettingCheck) Expectation() string {
+	format, ok := settingOps[c.Op]
+	if !ok {
+		format = c.Op + " %s"
+	}
+	return fmt.Sprintf(format, strings.Join(splitList(c.Value), ", "))
+}
+
+// Passes reports whether value satisfies the check. ok is false when the
+// value cannot be compared, e.g. a non-numeric value for min/max.
+func (c SettingCheck) Passes(value string) (pass, ok bool) {
+	expected := splitList(c.Value)
+
+	switch c.Op {
+	case "eq":
+		return strings.EqualFold(value, c.Value), true
+	case "ne":
+		return !strings.EqualFold(value, c.Value), true
+	case "in":
+		return containsFold(expected, value), true
+	case "not_in":
+		return !containsFold(expected, value), true
+	case "min", "max":
+		actual, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false, false
+		}
+		limit, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return false, false
+		}
+		if c.Op == "min" {
+			return actual >= limit, true
+		}
+		return actual <= limit, true
+	case "includes":
+		items := splitList(value)
+		for _, e := range expected {
+			if !containsFold(items, e) {
+				return false, true
+			}
+		}
+		return true, true
+	case "has":
+		for _, e := range expected {
+			if !strings.Contains(value, e) {
+				return false, true
+			}
+		}
+		return true, true
+	}
+
+	return false, false
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.Trim(strings.TrimSpace(item), `"`)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func containsFold(list []string, item string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, item) {
+			return true
+		}
+	}
+	return false
+}
+
+func settingNames(checks []SettingCheck) []string {
+	names := []string{"ssl"}
+	for _, c := range checks {
+		if !contains(names, c.Name) {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+func settingFindings(settings map[string]string, checks []SettingCheck) []Finding {
+	var findings []Finding
+
+	for _, c := range checks {
+		if c.Disabled {
+			continue
+		}
+
+		// Settings missing from this server version are not reported
+		value, ok := settings[c.Name]
+		if !ok {
+			continue
+		}
+
+		pass, comparable := c.Passes(value)
+		if pass || !comparable {
+			continue
+		}
+
+		var remediation string
+		switch c.Op {
+		case "eq", "in":
+			if c.Value == "" {
+				break
+			}
+			remediation = fmt.Sprintf("ALTER SYSTEM SET %s = '%s';", c.Name, splitList(c.Value)[0])
+		}
+
+		findings = append(findings, Finding{
+			Severity:    c.Severity,
+			Code:        "SETTING_MISMATCH",
+			Message:     fmt.Sprintf("Setting %s is %q, expected %s. %s", c.Name, value, c.Expectation(), c.Rationale),
+			ObjectType:  "setting",
+			Object:      c.Name,
+			Remediation: fix(remediation),
+		})
+	}
+
+	return findings
+}
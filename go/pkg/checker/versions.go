@@ -0,0 +1,197 @@
+package checker
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed releases.yaml
+var defaultReleasesYAML []byte
+
+// ReleaseData is the offline dataset of PostgreSQL releases and security fixes
+type ReleaseData struct {
+	Updated       time.Time      `yaml:"updated"`
+	Majors        []MajorRelease `yaml:"majors"`
+	SecurityFixes []SecurityFix  `yaml:"security_fixes"`
+}
+
+type MajorRelease struct {
+	Major       int       `yaml:"major"`
+	Released    time.Time `yaml:"released"`
+	EOL         time.Time `yaml:"eol"`
+	LatestMinor int       `yaml:"latest_minor"`
+}
+
+type SecurityFix struct {
+	CVE     string   `yaml:"cve"`
+	Summary string   `yaml:"summary"`
+	FixedIn []string `yaml:"fixed_in"`
+}
+
+// DefaultReleaseData returns the dataset bundled with this build
+func DefaultReleaseData() *ReleaseData {
+	data, err := parseReleaseData(defaultReleasesYAML)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in release data: %v", err))
+	}
+	return data
+}
+
+// LoadReleaseData reads a release dataset in the format of the bundled one
+func LoadReleaseData(path string) (*ReleaseData, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release data: %w", err)
+	}
+
+	data, err := parseReleaseData(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse release data: %w", err)
+	}
+
+	return data, nil
+}
+
+func parseReleaseData(raw []byte) (*ReleaseData, error) {
+	var data ReleaseData
+	if err := yaml.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	for _, fix := range data.SecurityFixes {
+		for _, v := range fix.FixedIn {
+			if _, _, ok := splitVersion(v); !ok {
+				return nil, fmt.Errorf("%s: invalid version %q", fix.CVE, v)
+			}
+		}
+	}
+
+	return &data, nil
+}
+
+// splitVersion parses "16.4" into 16 and 4
+func splitVersion(v string) (major, minor int, ok bool) {
+	majorStr, minorStr, found := strings.Cut(v, ".")
+	if !found {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(majorStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err = strconv.Atoi(minorStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// parseVersionNum splits server_version_num into major and minor versions.
+// Before PostgreSQL 10 the major version had two parts (90624 is 9.6.24);
+// those are reported as major 9.
+func parseVersionNum(num int) (major, minor int) {
+	if num >= 100000 {
+		return num / 10000, num % 10000
+	}
+	return num / 10000, num % 100
+}
+
+func formatVersionNum(num int) string {
+	if num >= 100000 {
+		return fmt.Sprintf("%d.%d", num/10000, num%10000)
+	}
+	return fmt.Sprintf("%d.%d.%d", num/10000, num/100%100, num%100)
+}
+
+func (d *ReleaseData) major(major int) (MajorRelease, bool) {
+	for _, m := range d.Majors {
+		if m.Major == major {
+			return m, true
+		}
+	}
+	return MajorRelease{}, false
+}
+
+func (d *ReleaseData) oldestMajor() int {
+	oldest := 0
+	for _, m := range d.Majors {
+		if oldest == 0 || m.Major < oldest {
+			oldest = m.Major
+		}
+	}
+	return oldest
+}
+
+// missingFixes returns the security fixes released for major after minor
+func (d *ReleaseData) missingFixes(major, minor int) []SecurityFix {
+	var missing []SecurityFix
+	for _, fix := range d.SecurityFixes {
+		for _, v := range fix.FixedIn {
+			fixMajor, fixMinor, _ := splitVersion(v)
+			if fixMajor == major && fixMinor > minor {
+				missing = append(missing, fix)
+				break
+			}
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].CVE < missing[j].CVE })
+	return missing
+}
+
+func versionFindings(info InstanceInfo, data *ReleaseData, now time.Time) []Finding {
+	if info.VersionNum == 0 || data == nil {
+		return nil
+	}
+
+	var findings []Finding
+	add := func(severity, code, message string) {
+		findings = append(findings, Finding{
+			Severity:   severity,
+			Code:       code,
+			Message:    message,
+			ObjectType: "instance",
+			Object:     info.Database,
+		})
+	}
+
+	current := formatVersionNum(info.VersionNum)
+
+	release, known := data.major(info.MajorVersion)
+	if !known {
+		// Majors newer than the dataset cannot be judged
+		if info.MajorVersion < data.oldestMajor() {
+			add("critical", "VERSION_EOL",
+				fmt.Sprintf("PostgreSQL %s is end-of-life and no longer receives security fixes", current))
+		}
+		return findings
+	}
+
+	if now.After(release.EOL) {
+		add("critical", "VERSION_EOL",
+			fmt.Sprintf("PostgreSQL %d reached end-of-life on %s and no longer receives security fixes",
+				release.Major, release.EOL.Format("2006-01-02")))
+	}
+
+	latest := fmt.Sprintf("%d.%d", release.Major, release.LatestMinor)
+	if missing := data.missingFixes(info.MajorVersion, info.MinorVersion); len(missing) > 0 {
+		cves := make([]string, len(missing))
+		for i, fix := range missing {
+			cves[i] = fix.CVE
+		}
+		add("high", "VERSION_MISSING_SECURITY_FIXES",
+			fmt.Sprintf("PostgreSQL %s misses %d security fix(es) available in %s: %s",
+				current, len(missing), latest, strings.Join(cves, ", ")))
+	} else if info.MinorVersion < release.LatestMinor {
+		add("warning", "VERSION_OUTDATED_MINOR",
+			fmt.Sprintf("PostgreSQL %s is behind the latest minor release %s", current, latest))
+	}
+
+	return findings
+}
@@ -101,7 +101,16 @@ func handleAnalyze(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Analyzing database: %s", maskDSN(req.DSN))
 
 	ctx := r.Context()
-	conn, err := pgx.Connect(ctx, req.DSN)
+	config, err := pgx.ParseConfig(req.DSN)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, AnalyzeResponse{
+			Error: fmt.Sprintf("Invalid DSN: %v", err),
+		})
+		return
+	}
+	checker.SetApplicationName(config)
+
+	conn, err := pgx.ConnectConfig(ctx, config)
 	if err != nil {
 		log.Printf("Connection failed: %v", err)
 		respondJSON(w, http.StatusBadRequest, AnalyzeResponse{
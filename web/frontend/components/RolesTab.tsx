@@ -32,6 +32,7 @@ export default function RolesTab({ report }: Props) {
 
 function RoleRow({ role }: { role: RoleInfo }) {
   const isDangerous = role.superuser || role.bypassrls;
+  const columnGrants = role.column_grants ?? [];
 
   return (
     <tr className={isDangerous ? 'bg-red-50' : ''}>
@@ -46,7 +47,21 @@ function RoleRow({ role }: { role: RoleInfo }) {
       <td className="px-6 py-4"><Badge active={role.login} /></td>
       <td className="px-6 py-4"><Badge active={role.superuser} danger /></td>
       <td className="px-6 py-4"><Badge active={role.bypassrls} danger /></td>
-      <td className="px-6 py-4 text-sm text-gray-600">{role.grants.length} grants</td>
+      <td className="px-6 py-4 text-sm text-gray-600">
+        <div>{role.grants.length} grants</div>
+        {columnGrants.length > 0 && (
+          <div className="mt-1">
+            <div>{columnGrants.length} column grants</div>
+            <ul className="mt-1 text-xs font-mono text-gray-500">
+              {columnGrants.map((g) => (
+                <li key={`${g.privilege}:${g.object}:${g.column}`}>
+                  {g.privilege} ({g.column}) ON {g.object}
+                </li>
+              ))}
+            </ul>
+          </div>
+        )}
+      </td>
     </tr>
   );
 }
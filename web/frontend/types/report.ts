@@ -1,6 +1,12 @@
 export interface InstanceInfo {
   version: string;
+  version_num?: number;
+  major_version?: number;
+  minor_version?: number;
+  stats_reset?: string;
+  database?: string;
   settings: Record<string, string>;
+  restart_settings?: string[];
 }
 
 export interface RoleInfo {
@@ -9,25 +15,222 @@ export interface RoleInfo {
   superuser: boolean;
   bypassrls: boolean;
   grants: string[];
+  column_grants?: ColumnGrant[];
+  member_of?: Membership[];
+  effective_roles?: string[];
+}
+
+export interface ColumnGrant {
+  object: string;
+  column: string;
+  privilege: string;
+}
+
+export interface Membership {
+  role: string;
+  grantor?: string;
+  admin_option: boolean;
+  inherit_option: boolean;
+  set_option: boolean;
+}
+
+export interface PolicyInfo {
+  name: string;
+  command: string;
+  permissive: boolean;
+  roles: string[];
+  using?: string;
+  with_check?: string;
 }
 
 export interface TableInfo {
   schema: string;
   name: string;
   rls_enabled: boolean;
+  rls_forced?: boolean;
+  owner?: string;
+  owner_login?: boolean;
+  policies?: PolicyInfo[];
+  accesses?: number;
 }
 
 export type Severity = "info" | "warning" | "critical";
 
+export interface Remediation {
+  explanation: string;
+  sql?: string;
+  risk?: string;
+  safe_to_automate: boolean;
+}
+
 export interface Finding {
   severity: Severity;
   code: string;
   message: string;
+  database?: string;
+  object_type?: string;
+  object?: string;
+  remediation?: Remediation;
+}
+
+export interface PublicGrant {
+  object_type: string;
+  object: string;
+  privilege: string;
+}
+
+export interface Grant {
+  grantor: string;
+  grantee: string;
+  object_kind: string;
+  object: string;
+  owner: string;
+  privilege: string;
+  grantable: boolean;
+}
+
+export interface DefaultPrivilege {
+  role: string;
+  schema?: string;
+  object_type: string;
+  grantee: string;
+  grantee_login: boolean;
+  privilege: string;
+  grantable: boolean;
+}
+
+export interface FunctionInfo {
+  schema: string;
+  name: string;
+  signature: string;
+  owner: string;
+  owner_superuser: boolean;
+  language: string;
+  language_trusted: boolean;
+  security_definer: boolean;
+  search_path?: string;
+  public_execute: boolean;
+  executors: string[];
+}
+
+export interface ExtensionInfo {
+  name: string;
+  version: string;
+  schema: string;
+  default_version?: string;
+  available_versions: string[];
+}
+
+export interface LanguageInfo {
+  name: string;
+  trusted: boolean;
+  owner: string;
+  functions: number;
+}
+
+export interface PIIColumn {
+  schema: string;
+  table: string;
+  column: string;
+  data_type: string;
+  categories: string[];
+  sources: ("name" | "type" | "comment" | "sample")[];
+  masked: boolean;
+}
+
+export interface RoleActivity {
+  role: string;
+  sessions: number;
+  last_seen?: string;
+  owned_objects: number;
+  acl_entries: number;
+}
+
+export interface RoleCleanup {
+  role: string;
+  database?: string;
+  reasons: string[];
+  sql: string[];
+}
+
+export interface SessionInfo {
+  pid: number;
+  backend_start?: string;
+  user: string;
+  database: string;
+  client_addr?: string;
+  application_name?: string;
+  state: string;
+  state_seconds: number;
+  ssl: boolean;
+  ssl_version?: string;
+  gss_authenticated: boolean;
+  gss_encrypted: boolean;
+  superuser: boolean;
+  locks_held: number;
+}
+
+export type ControlStatus = "pass" | "fail" | "not_applicable" | "manual";
+
+export interface ControlResult {
+  id: string;
+  title: string;
+  status: ControlStatus;
+  findings?: Finding[];
+}
+
+export interface ProfileResult {
+  id: string;
+  title: string;
+  summary: Record<ControlStatus, number>;
+  controls: ControlResult[];
+}
+
+export interface DatabaseReport {
+  name: string;
+  stats_reset?: string;
+  roles: RoleInfo[];
+  tables: TableInfo[];
+  public_grants: PublicGrant[];
+  grants: Grant[];
+  default_privileges: DefaultPrivilege[];
+  functions: FunctionInfo[];
+  extensions: ExtensionInfo[];
+  languages: LanguageInfo[];
+  pii_columns: PIIColumn[];
+  findings: Finding[];
+}
+
+export interface CollectorTiming {
+  collector: string;
+  database?: string;
+  duration_ms: number;
+}
+
+export interface CoverageGap {
+  collector: string;
+  database?: string;
+  reason: string;
+  grant?: string;
 }
 
 export interface PolicyReport {
   instance: InstanceInfo;
   roles: RoleInfo[];
   tables: TableInfo[];
+  public_grants?: PublicGrant[];
+  grants?: Grant[];
+  default_privileges?: DefaultPrivilege[];
+  functions?: FunctionInfo[];
+  extensions?: ExtensionInfo[];
+  languages?: LanguageInfo[];
+  pii_columns?: PIIColumn[];
+  role_activity?: RoleActivity[];
+  role_cleanup?: RoleCleanup[];
+  sessions?: SessionInfo[];
   findings: Finding[];
+  profile?: ProfileResult;
+  databases?: DatabaseReport[];
+  timings?: CollectorTiming[];
+  coverage_gaps?: CoverageGap[];
 }